@@ -0,0 +1,239 @@
+package icws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/gildas/go-errors"
+)
+
+// ConfigurationID identifies a PureConnect configuration object
+type ConfigurationID struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName,omitempty"`
+	URI         string `json:"uri,omitempty"`
+}
+
+// ConfigurationObject describes a PureConnect configuration object (user, workgroup, role, etc)
+type ConfigurationObject interface {
+	Identifiable
+	// configurationPath tells the path of the objects of this type
+	configurationPath() string
+}
+
+// GetID tells the ID
+//
+// implements Identifiable
+func (id ConfigurationID) GetID() string {
+	return id.ID
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (id ConfigurationID) String() string {
+	if len(id.DisplayName) > 0 {
+		return id.DisplayName
+	}
+	return id.ID
+}
+
+// GetConfiguration retrieves a configuration object
+//
+// Use options.Fields to select the properties to retrieve
+func GetConfiguration[T ConfigurationObject](session *Session, id string, options QueryOptions) (*T, error) {
+	return GetConfigurationContext[T](session.Context, session, id, options)
+}
+
+// GetConfigurationContext retrieves a configuration object
+//
+// The context cancels the request
+func GetConfigurationContext[T ConfigurationObject](context context.Context, session *Session, id string, options QueryOptions) (*T, error) {
+	if len(id) == 0 {
+		return nil, errors.ArgumentMissing.With("id")
+	}
+	var object T
+	_, err := session.sendContext(context, http.MethodGet, object.configurationPath()+"/"+url.PathEscape(id), nil, options.AsQueryParameters(), nil, &object)
+	if err != nil {
+		return nil, err
+	}
+	return &object, nil
+}
+
+// ListConfiguration gives a Cursor on the configuration objects of type T
+func ListConfiguration[T ConfigurationObject](session *Session, options PageOptions) *Cursor[T] {
+	var object T
+	return Paginate[T](session, object.configurationPath(), options)
+}
+
+// ListConfigurationContext gives a Cursor on the configuration objects of type T
+//
+// The context cancels the walk, it replaces options.Context
+func ListConfigurationContext[T ConfigurationObject](context context.Context, session *Session, options PageOptions) *Cursor[T] {
+	options.Context = context
+	return ListConfiguration[T](session, options)
+}
+
+// CreateConfiguration creates a configuration object
+//
+// All the properties are sent, even false, 0 or empty ones, except the null ones (nil slices and pointers)
+func CreateConfiguration[T ConfigurationObject](session *Session, object T) (*ConfigurationID, error) {
+	return CreateConfigurationContext(session.Context, session, object)
+}
+
+// CreateConfigurationContext creates a configuration object
+//
+// The context cancels the request
+func CreateConfigurationContext[T ConfigurationObject](context context.Context, session *Session, object T) (*ConfigurationID, error) {
+	if len(object.GetID()) == 0 {
+		return nil, errors.ArgumentMissing.With("id")
+	}
+	properties, err := asJSONProperties(object)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range properties {
+		if bytes.Equal(value, []byte("null")) {
+			delete(properties, name)
+		}
+	}
+	results := struct {
+		ConfigurationID ConfigurationID `json:"configurationId"`
+	}{}
+	if err = session.sendPostContext(context, object.configurationPath(), configurationPayload{properties}, &results); err != nil {
+		return nil, err
+	}
+	if len(results.ConfigurationID.ID) == 0 {
+		results.ConfigurationID.ID = object.GetID()
+	}
+	return &results.ConfigurationID, nil
+}
+
+// UpdateConfiguration updates a configuration object
+//
+// Only the properties of updated that differ from original are sent.
+// Nested objects are compared property by property, only their changed properties are sent.
+// Arrays are sent as a whole when they differ.
+// Nothing is sent if there are no changes.
+func UpdateConfiguration[T ConfigurationObject](session *Session, original, updated T) error {
+	return UpdateConfigurationContext(session.Context, session, original, updated)
+}
+
+// UpdateConfigurationContext updates a configuration object
+//
+// The context cancels the request
+func UpdateConfigurationContext[T ConfigurationObject](context context.Context, session *Session, original, updated T) error {
+	if len(original.GetID()) == 0 {
+		return errors.ArgumentMissing.With("id")
+	}
+	changes, err := configurationChanges(original, updated)
+	if err != nil {
+		return err
+	}
+	delete(changes, "configurationId")
+	if len(changes) == 0 {
+		return nil
+	}
+	return session.sendPutContext(context, original.configurationPath()+"/"+url.PathEscape(original.GetID()), configurationPayload{changes}, nil)
+}
+
+// DeleteConfiguration deletes a configuration object
+func DeleteConfiguration[T ConfigurationObject](session *Session, id string) error {
+	return DeleteConfigurationContext[T](session.Context, session, id)
+}
+
+// DeleteConfigurationContext deletes a configuration object
+//
+// The context cancels the request
+func DeleteConfigurationContext[T ConfigurationObject](context context.Context, session *Session, id string) error {
+	if len(id) == 0 {
+		return errors.ArgumentMissing.With("id")
+	}
+	var object T
+	return session.sendDeleteContext(context, object.configurationPath()+"/"+url.PathEscape(id))
+}
+
+// configurationPayload sends configuration properties as a JSON object
+//
+// maps would be sent as forms
+type configurationPayload struct {
+	properties map[string]json.RawMessage
+}
+
+// MarshalJSON marshals into JSON
+//
+// implements json.Marshaler
+func (payload configurationPayload) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(payload.properties)
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// configurationChanges gives the JSON properties of updated that differ from original
+//
+// Nested objects are compared recursively, their "__type" is kept so PureConnect can decode them.
+func configurationChanges(original, updated interface{}) (map[string]json.RawMessage, error) {
+	before, err := asJSONProperties(original)
+	if err != nil {
+		return nil, err
+	}
+	after, err := asJSONProperties(updated)
+	if err != nil {
+		return nil, err
+	}
+	return jsonChanges(before, after), nil
+}
+
+// jsonChanges gives the properties of after that differ from before, removed properties become null
+func jsonChanges(before, after map[string]json.RawMessage) map[string]json.RawMessage {
+	changes := map[string]json.RawMessage{}
+	for name, value := range after {
+		previous, found := before[name]
+		if found && bytes.Equal(previous, value) {
+			continue
+		}
+		if found && isJSONObject(previous) && isJSONObject(value) {
+			var nestedBefore, nestedAfter map[string]json.RawMessage
+			if json.Unmarshal(previous, &nestedBefore) == nil && json.Unmarshal(value, &nestedAfter) == nil {
+				nested := jsonChanges(nestedBefore, nestedAfter)
+				if len(nested) == 0 {
+					continue
+				}
+				if objectType, found := nestedAfter["__type"]; found {
+					nested["__type"] = objectType
+				}
+				if payload, err := json.Marshal(nested); err == nil {
+					changes[name] = payload
+					continue
+				}
+			}
+		}
+		changes[name] = value
+	}
+	for name := range before {
+		if _, found := after[name]; !found {
+			changes[name] = json.RawMessage("null")
+		}
+	}
+	return changes
+}
+
+// isJSONObject tells if the JSON value is an object
+func isJSONObject(value json.RawMessage) bool {
+	value = bytes.TrimSpace(value)
+	return len(value) > 0 && value[0] == '{'
+}
+
+func asJSONProperties(object interface{}) (map[string]json.RawMessage, error) {
+	payload, err := json.Marshal(object)
+	if err != nil {
+		return nil, errors.JSONMarshalError.Wrap(err)
+	}
+	properties := map[string]json.RawMessage{}
+	if err = json.Unmarshal(payload, &properties); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	return properties, nil
+}
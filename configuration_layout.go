@@ -0,0 +1,27 @@
+package icws
+
+// LayoutConfiguration describes the configuration of a PureConnect Layout
+type LayoutConfiguration struct {
+	ConfigurationID ConfigurationID `json:"configurationId"`
+	Description     string          `json:"description"`
+	CreatedAt       *Time           `json:"createdDate,omitempty"`
+	ModifiedAt      *Time           `json:"modifiedDate,omitempty"`
+}
+
+// GetID tells the ID
+//
+// implements Identifiable
+func (configuration LayoutConfiguration) GetID() string {
+	return configuration.ConfigurationID.ID
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (configuration LayoutConfiguration) String() string {
+	return configuration.ConfigurationID.String()
+}
+
+func (configuration LayoutConfiguration) configurationPath() string {
+	return "/configuration/layouts"
+}
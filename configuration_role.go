@@ -0,0 +1,28 @@
+package icws
+
+// RoleConfiguration describes the configuration of a PureConnect Role
+type RoleConfiguration struct {
+	ConfigurationID ConfigurationID   `json:"configurationId"`
+	Users           []ConfigurationID `json:"users"`
+	Layouts         []ConfigurationID `json:"layouts"`
+	CreatedAt       *Time             `json:"createdDate,omitempty"`
+	ModifiedAt      *Time             `json:"modifiedDate,omitempty"`
+}
+
+// GetID tells the ID
+//
+// implements Identifiable
+func (configuration RoleConfiguration) GetID() string {
+	return configuration.ConfigurationID.ID
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (configuration RoleConfiguration) String() string {
+	return configuration.ConfigurationID.String()
+}
+
+func (configuration RoleConfiguration) configurationPath() string {
+	return "/configuration/roles"
+}
@@ -0,0 +1,37 @@
+package icws
+
+// SkillConfiguration describes the configuration of a PureConnect Skill
+type SkillConfiguration struct {
+	ConfigurationID      ConfigurationID   `json:"configurationId"`
+	UserAssignments      []SkillAssignment `json:"userAssignments"`
+	WorkgroupAssignments []SkillAssignment `json:"workgroupAssignments"`
+	CreatedAt            *Time             `json:"createdDate,omitempty"`
+	ModifiedAt           *Time             `json:"modifiedDate,omitempty"`
+}
+
+// GetID tells the ID
+//
+// implements Identifiable
+func (configuration SkillConfiguration) GetID() string {
+	return configuration.ConfigurationID.ID
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (configuration SkillConfiguration) String() string {
+	return configuration.ConfigurationID.String()
+}
+
+func (configuration SkillConfiguration) configurationPath() string {
+	return "/configuration/skills"
+}
+
+// SkillAssignment describes the assignment of a Skill to a User or a Workgroup
+//
+// ID is the Skill when assigned to a User or Workgroup, the User or Workgroup when listed in a Skill
+type SkillAssignment struct {
+	ID          ConfigurationID `json:"id"`
+	Proficiency int             `json:"proficiency"`
+	DesireToUse int             `json:"desireToUse"`
+}
@@ -0,0 +1,30 @@
+package icws
+
+// StationConfiguration describes the configuration of a PureConnect Station
+type StationConfiguration struct {
+	ConfigurationID       ConfigurationID  `json:"configurationId"`
+	Extension             string           `json:"extension"`
+	IdentificationAddress string           `json:"identificationAddress"`
+	IsActive              bool             `json:"isActive"`
+	Location              *ConfigurationID `json:"location,omitempty"`
+	CreatedAt             *Time            `json:"createdDate,omitempty"`
+	ModifiedAt            *Time            `json:"modifiedDate,omitempty"`
+}
+
+// GetID tells the ID
+//
+// implements Identifiable
+func (configuration StationConfiguration) GetID() string {
+	return configuration.ConfigurationID.ID
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (configuration StationConfiguration) String() string {
+	return configuration.ConfigurationID.String()
+}
+
+func (configuration StationConfiguration) configurationPath() string {
+	return "/configuration/stations"
+}
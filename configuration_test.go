@@ -0,0 +1,84 @@
+package icws_test
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gildas/go-icws"
+)
+
+func (suite *SessionSuite) TestCanGetConfiguration() {
+	session, _ := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["GET /icws/1234/configuration/workgroups/Sales"] = `{
+			"configurationId": {"id": "Sales", "displayName": "Sales", "uri": "/configuration/workgroups/Sales"},
+			"extension": "8001",
+			"hasQueue": true,
+			"isActive": true,
+			"members": [{"id": "agent", "displayName": "Agent"}],
+			"skills": [{"id": {"id": "French"}, "proficiency": 80, "desireToUse": 50}],
+			"createdDate": "20201029T082018Z"
+		}`
+	})
+
+	workgroup, err := icws.GetConfiguration[icws.WorkgroupConfiguration](session, "Sales", icws.QueryOptions{Fields: []string{"extension", "members"}})
+	suite.Require().Nil(err)
+	suite.Require().NotNil(workgroup)
+	suite.Assert().Equal("Sales", workgroup.GetID())
+	suite.Assert().Equal("8001", workgroup.Extension)
+	suite.Assert().True(workgroup.HasQueue)
+	suite.Require().Len(workgroup.Members, 1)
+	suite.Assert().Equal("Agent", workgroup.Members[0].String())
+	suite.Require().Len(workgroup.Skills, 1)
+	suite.Assert().Equal(80, workgroup.Skills[0].Proficiency)
+	suite.Require().NotNil(workgroup.CreatedAt)
+}
+
+func (suite *SessionSuite) TestCanCreateUpdateAndDeleteConfiguration() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["POST /icws/1234/configuration/users"] = `{"configurationId": {"id": "newbie", "displayName": "newbie", "uri": "/configuration/users/newbie"}}`
+	})
+
+	user := icws.UserConfiguration{
+		ConfigurationID: icws.ConfigurationID{ID: "newbie"},
+		Extension:       "7010",
+	}
+	id, err := icws.CreateConfiguration(session, user)
+	suite.Require().Nil(err)
+	suite.Assert().Equal("/configuration/users/newbie", id.URI)
+	suite.Assert().JSONEq(`{
+		"configurationId": {"id": "newbie"},
+		"extension": "7010",
+		"ntDomainUser": "",
+		"personalInformationProperties": {"departmentName": "", "emailAddress": "", "givenName": "", "surname": "", "title": ""},
+		"licenseProperties": {"licenseActive": false, "hasClientAccess": false, "mediaLevel": 0, "allocationType": 0, "interactionProcessorAutomationType": 0, "additionalLicenses": null},
+		"mwiEnabled": false
+	}`, server.Payload(http.MethodPost, "/icws/1234/configuration/users"), "Creating should send the false, 0 and empty properties too")
+
+	updated := user
+	updated.MWIEnabled = true
+	updated.Roles = []icws.ConfigurationID{{ID: "Agent"}}
+	suite.Require().Nil(icws.UpdateConfiguration(session, user, updated))
+	suite.Assert().JSONEq(`{"mwiEnabled": true, "roles": [{"id": "Agent"}]}`, server.Payload(http.MethodPut, "/icws/1234/configuration/users/newbie"))
+
+	suite.Require().Nil(icws.UpdateConfiguration(session, updated, updated))
+	suite.Assert().Equal(1, server.Count(http.MethodPut, "/icws/1234/configuration/users/newbie"), "Unchanged objects should not be sent")
+
+	suite.Require().Nil(icws.DeleteConfiguration[icws.UserConfiguration](session, "newbie"))
+	suite.Assert().Equal(1, server.Count(http.MethodDelete, "/icws/1234/configuration/users/newbie"))
+}
+
+func (suite *SessionSuite) TestShouldUpdateOnlyChangedNestedProperties() {
+	session, server := suite.NewConnectedSession()
+
+	user := icws.UserConfiguration{
+		ConfigurationID:     icws.ConfigurationID{ID: "agent"},
+		PersonalInformation: icws.PersonalInformation{GivenName: "John", Surname: "Doe", Title: "Agent"},
+	}
+	updated := user
+	updated.PersonalInformation.Title = "Supervisor"
+	suite.Require().Nil(icws.UpdateConfigurationContext(context.Background(), session, user, updated))
+	suite.Assert().JSONEq(`{"personalInformationProperties": {"title": "Supervisor"}}`, server.Payload(http.MethodPut, "/icws/1234/configuration/users/agent"))
+
+	suite.Require().Nil(icws.DeleteConfigurationContext[icws.UserConfiguration](context.Background(), session, "agent"))
+	suite.Assert().Equal(1, server.Count(http.MethodDelete, "/icws/1234/configuration/users/agent"))
+}
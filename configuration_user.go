@@ -0,0 +1,45 @@
+package icws
+
+// UserConfiguration describes the configuration of a PureConnect User
+type UserConfiguration struct {
+	ConfigurationID     ConfigurationID     `json:"configurationId"`
+	Extension           string              `json:"extension"`
+	NTDomainUser        string              `json:"ntDomainUser"`
+	DefaultWorkstation  *ConfigurationID    `json:"defaultWorkstation,omitempty"`
+	Workgroups          []ConfigurationID   `json:"workgroups"`
+	Roles               []ConfigurationID   `json:"roles"`
+	Skills              []SkillAssignment   `json:"skills"`
+	Layouts             []ConfigurationID   `json:"layouts"`
+	PersonalInformation PersonalInformation `json:"personalInformationProperties"`
+	LicenseProperties   LicenseProperties   `json:"licenseProperties"`
+	MWIEnabled          bool                `json:"mwiEnabled"`
+	CreatedAt           *Time               `json:"createdDate,omitempty"`
+	ModifiedAt          *Time               `json:"modifiedDate,omitempty"`
+}
+
+// GetID tells the ID
+//
+// implements Identifiable
+func (configuration UserConfiguration) GetID() string {
+	return configuration.ConfigurationID.ID
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (configuration UserConfiguration) String() string {
+	return configuration.ConfigurationID.String()
+}
+
+func (configuration UserConfiguration) configurationPath() string {
+	return "/configuration/users"
+}
+
+// PersonalInformation describes the personal information of a PureConnect User
+type PersonalInformation struct {
+	GivenName    string `json:"givenName"`
+	Surname      string `json:"surname"`
+	EmailAddress string `json:"emailAddress"`
+	Department   string `json:"departmentName"`
+	Title        string `json:"title"`
+}
@@ -0,0 +1,33 @@
+package icws
+
+// WorkgroupConfiguration describes the configuration of a PureConnect Workgroup
+type WorkgroupConfiguration struct {
+	ConfigurationID ConfigurationID   `json:"configurationId"`
+	Extension       string            `json:"extension"`
+	HasQueue        bool              `json:"hasQueue"`
+	IsActive        bool              `json:"isActive"`
+	Members         []ConfigurationID `json:"members"`
+	Supervisors     []ConfigurationID `json:"supervisors"`
+	Skills          []SkillAssignment `json:"skills"`
+	Layouts         []ConfigurationID `json:"layouts"`
+	CreatedAt       *Time             `json:"createdDate,omitempty"`
+	ModifiedAt      *Time             `json:"modifiedDate,omitempty"`
+}
+
+// GetID tells the ID
+//
+// implements Identifiable
+func (configuration WorkgroupConfiguration) GetID() string {
+	return configuration.ConfigurationID.ID
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (configuration WorkgroupConfiguration) String() string {
+	return configuration.ConfigurationID.String()
+}
+
+func (configuration WorkgroupConfiguration) configurationPath() string {
+	return "/configuration/workgroups"
+}
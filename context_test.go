@@ -0,0 +1,182 @@
+package icws_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-icws"
+)
+
+// blockUntilCanceled blocks the request until the client gives up, or for 5 seconds
+func blockUntilCanceled(w http.ResponseWriter, r *http.Request) {
+	select {
+	case <-r.Context().Done():
+	case <-time.After(5 * time.Second):
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (suite *SessionSuite) TestCanCancelRequest() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Handlers["GET /icws/1234/configuration/users"] = blockUntilCanceled
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := session.GetUsersContext(ctx)
+	suite.Require().NotNil(err, "The request should be canceled")
+	suite.Assert().True(errors.Is(err, context.Canceled))
+	suite.Assert().Less(time.Since(start), 1*time.Second)
+	suite.Assert().Equal(1, server.Count(http.MethodGet, "/icws/1234/configuration/users"), "A canceled request should not be retried")
+	suite.Assert().True(session.IsConnected())
+}
+
+func (suite *SessionSuite) TestShouldPropagateDeadlineToRequests() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Handlers["GET /icws/1234/configuration/users"] = blockUntilCanceled
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := session.GetUsersWithOptionsContext(ctx, icws.QueryOptions{})
+	suite.Require().NotNil(err, "The request should time out")
+	suite.Assert().True(errors.Is(err, context.DeadlineExceeded))
+	suite.Assert().Less(time.Since(start), 1*time.Second)
+	suite.Assert().Equal(1, server.Count(http.MethodGet, "/icws/1234/configuration/users"), "There is no time left to retry after the deadline")
+}
+
+func (suite *SessionSuite) TestShouldNotConnectWithCanceledContext() {
+	server := NewFakeServer()
+	defer server.Close()
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:     []*url.URL{server.URL("127.0.0.1")},
+		UserID:      "agent",
+		Password:    "s3cr3t",
+		Application: "test",
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := session.ConnectContext(ctx)
+	suite.Require().NotNil(err)
+	suite.Assert().True(errors.Is(err, context.Canceled))
+	suite.Assert().False(session.IsConnected())
+	suite.Assert().Equal(0, server.LoginCount())
+
+	_, err = session.GetVersionContext(ctx)
+	suite.Assert().True(errors.Is(err, context.Canceled), "Requests should not connect the Session with a canceled context")
+	suite.Assert().Equal(0, server.LoginCount())
+}
+
+func (suite *SessionSuite) TestCanCancelEventStreamConnection() {
+	server := NewFakeServer()
+	defer server.Close()
+	server.Handlers["GET /icws/1234/messaging/messages"] = blockUntilCanceled
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:     []*url.URL{server.URL("127.0.0.1")},
+		UserID:      "agent",
+		Password:    "s3cr3t",
+		Application: "test",
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := session.ConnectContext(ctx)
+	suite.Require().NotNil(err, "Connect should give up on the Server-Sent Events")
+	suite.Assert().True(errors.Is(err, context.DeadlineExceeded))
+	suite.Assert().Less(time.Since(start), 1*time.Second)
+}
+
+func (suite *SessionSuite) TestCanSubscribeWithContext() {
+	session, _ := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Handlers["PUT /icws/1234/messaging/subscriptions/licenses"] = blockUntilCanceled
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := session.SubscribeContext(ctx, icws.LicenseMessage{}, icws.LicenseSubscription{Licenses: []string{"I3_ACCESS_CLIENT"}})
+	suite.Require().NotNil(err)
+	suite.Assert().True(errors.Is(err, context.DeadlineExceeded))
+	_, found := session.GetSubscription(icws.LicenseMessage{}.GetType())
+	suite.Assert().False(found, "A canceled subscription should not be recorded")
+
+	handle, err := session.SubscribeWithHandleContext(context.Background(), icws.UserStatusMessage{}, icws.UserStatusSubscription{UserIDs: []string{"agent"}})
+	suite.Require().Nil(err)
+	suite.Require().Nil(session.UnsubscribeContext(context.Background(), handle.Subscriber))
+}
+
+func (suite *SessionSuite) TestCanCancelSessionCalls() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Handlers["POST /icws/1234/interactions/2001/pickup"] = blockUntilCanceled
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := session.PickupInteractionContext(ctx, "2001")
+	suite.Require().NotNil(err, "The request should time out")
+	suite.Assert().True(errors.Is(err, context.DeadlineExceeded))
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = session.MakeCallContext(canceled, "+13175551234", icws.MakeCallOptions{})
+	suite.Assert().True(errors.Is(err, context.Canceled), "MakeCallContext should be canceled")
+	err = session.SetUserStatusContext(canceled, "agent", "Available", icws.UserStatusOptions{})
+	suite.Assert().True(errors.Is(err, context.Canceled), "SetUserStatusContext should be canceled")
+	err = session.AssignSkillContext(canceled, icws.User{ID: "agent"}, "French", 50, 50)
+	suite.Assert().True(errors.Is(err, context.Canceled), "AssignSkillContext should be canceled")
+	err = session.DialerLogonContext(canceled, icws.DialerCampaign{ID: "c1"})
+	suite.Assert().True(errors.Is(err, context.Canceled), "DialerLogonContext should be canceled")
+	err = session.ConnectStationContext(canceled, icws.WorkStationSettings{Workstation: "7001"})
+	suite.Assert().True(errors.Is(err, context.Canceled), "ConnectStationContext should be canceled")
+	suite.Assert().Nil(session.StationSettings)
+	_, err = session.GetLicenseReportContext(canceled, icws.QueryOptions{})
+	suite.Assert().True(errors.Is(err, context.Canceled), "GetLicenseReportContext should be canceled")
+
+	suite.Assert().Equal(0, server.Count(http.MethodPost, "/icws/1234/interactions"))
+	suite.Assert().Equal(0, server.Count(http.MethodPut, "/icws/1234/status/user-statuses/agent"))
+	suite.Assert().Equal(0, server.Count(http.MethodPost, "/icws/1234/dialer/logon"))
+	suite.Assert().Equal(0, server.Count(http.MethodPut, "/icws/1234/connection/station"))
+}
+
+func (suite *SessionSuite) TestCanCancelTrackerCalls() {
+	session, server := suite.NewConnectedSession()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := icws.NewStatusMessageCatalogContext(canceled, session)
+	suite.Assert().True(errors.Is(err, context.Canceled), "NewStatusMessageCatalogContext should be canceled")
+	_, err = icws.NewLicenseTrackerContext(canceled, session, "I3_ACCESS_CLIENT")
+	suite.Assert().True(errors.Is(err, context.Canceled), "NewLicenseTrackerContext should be canceled")
+
+	catalog, err := icws.NewStatusMessageCatalog(session)
+	suite.Require().Nil(err)
+	defer catalog.Close()
+	_, err = catalog.AllowedForContext(canceled, "agent")
+	suite.Assert().True(errors.Is(err, context.Canceled), "AllowedForContext should be canceled")
+
+	roster := icws.NewUserStatusRoster(session)
+	defer roster.Close()
+	err = roster.AddContext(canceled, "supervisor")
+	suite.Assert().True(errors.Is(err, context.Canceled), "AddContext should be canceled")
+	suite.Assert().Empty(roster.Users(), "The User should not be added")
+	suite.Require().Nil(roster.Add("supervisor"))
+	err = roster.RemoveContext(canceled, "supervisor")
+	suite.Assert().True(errors.Is(err, context.Canceled), "RemoveContext should be canceled")
+
+	handle, err := session.SubscribeWithHandle(icws.LicenseMessage{}, icws.LicenseSubscription{Licenses: []string{"I3_ACCESS_CLIENT"}})
+	suite.Require().Nil(err)
+	err = handle.UpdateContext(canceled, icws.LicenseSubscription{Licenses: []string{"I3_ACCESS_DIALER_ADDON"}})
+	suite.Assert().True(errors.Is(err, context.Canceled), "UpdateContext should be canceled")
+	err = handle.UnsubscribeContext(canceled)
+	suite.Assert().True(errors.Is(err, context.Canceled), "UnsubscribeContext should be canceled")
+
+	suite.Assert().Equal(1, server.Count(http.MethodPut, "/icws/1234/messaging/subscriptions/licenses"), "Only the subscription with a live context should be sent")
+	suite.Assert().Equal(0, server.Count(http.MethodDelete, "/icws/1234/messaging/subscriptions/licenses"))
+	suite.Assert().Equal(0, server.Count(http.MethodGet, "/icws/1234/status/status-messages-user-access/agent"))
+}
@@ -0,0 +1,261 @@
+package icws
+
+import (
+	"context"
+
+	"github.com/gildas/go-errors"
+)
+
+// DialerCampaign describes an Interaction Dialer campaign
+type DialerCampaign struct {
+	ID     string               `json:"id"`
+	Name   string               `json:"name"`
+	Status DialerCampaignStatus `json:"status"`
+	Mode   DialerMode           `json:"dialingMode"`
+}
+
+// DialerCampaignStatus is the status of a DialerCampaign
+type DialerCampaignStatus string
+
+const (
+	CampaignRunning  DialerCampaignStatus = "Running"
+	CampaignIdle     DialerCampaignStatus = "Idle"
+	CampaignStopping DialerCampaignStatus = "Stopping"
+	CampaignOff      DialerCampaignStatus = "Off"
+)
+
+// DialerMode is the dialing mode of a DialerCampaign
+type DialerMode string
+
+const (
+	PredictiveMode DialerMode = "Predictive"
+	PowerMode      DialerMode = "Power"
+	PreviewMode    DialerMode = "Preview"
+	AgentlessMode  DialerMode = "Agentless"
+)
+
+// DialerWrapupCategory is the category of the result of a Dialer call
+type DialerWrapupCategory string
+
+const (
+	SuccessWrapup   DialerWrapupCategory = "Success"
+	FailureWrapup   DialerWrapupCategory = "Failure"
+	BusyWrapup      DialerWrapupCategory = "Busy"
+	NoAnswerWrapup  DialerWrapupCategory = "No Answer"
+	MachineWrapup   DialerWrapupCategory = "Machine"
+	FaxWrapup       DialerWrapupCategory = "Fax"
+	SITWrapup       DialerWrapupCategory = "SIT"
+	ScheduledWrapup DialerWrapupCategory = "Scheduled"
+)
+
+// DialerDisposition describes how a Dialer call ended
+type DialerDisposition struct {
+	InteractionID  string               `json:"interactionId"`
+	CallIDKey      string               `json:"callIdKey"`
+	WrapupCategory DialerWrapupCategory `json:"wrapupCategory"`
+	ReasonCode     string               `json:"reasonCode"`
+	// CallbackAt schedules a callback, WrapupCategory should be ScheduledWrapup
+	CallbackAt *Time `json:"callbackDateTime,omitempty"`
+}
+
+// GetID tells the ID
+//
+// implements Identifiable
+func (campaign DialerCampaign) GetID() string {
+	return campaign.ID
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (campaign DialerCampaign) String() string {
+	if len(campaign.Name) > 0 {
+		return campaign.Name
+	}
+	return campaign.ID
+}
+
+// GetDialerCampaigns retrieves the Dialer campaigns the User can log on
+func (session *Session) GetDialerCampaigns() ([]DialerCampaign, error) {
+	return session.GetDialerCampaignsContext(session.Context)
+}
+
+// GetDialerCampaignsContext retrieves the Dialer campaigns the User can log on
+//
+// The context cancels the request
+func (session *Session) GetDialerCampaignsContext(context context.Context) ([]DialerCampaign, error) {
+	results := struct {
+		Campaigns []DialerCampaign `json:"campaigns"`
+	}{}
+	if err := session.sendGetContext(context, "/dialer/campaigns", &results); err != nil {
+		return nil, err
+	}
+	return results.Campaigns, nil
+}
+
+// SubscribeDialer subscribes the Session to the Dialer campaign changes, call data, and granted breaks
+func (session *Session) SubscribeDialer() error {
+	return session.SubscribeDialerContext(session.Context)
+}
+
+// SubscribeDialerContext subscribes the Session to the Dialer campaign changes, call data, and granted breaks
+//
+// The context cancels the requests
+func (session *Session) SubscribeDialerContext(context context.Context) error {
+	if err := session.SubscribeContext(context, DialerCampaignMessage{}, struct{}{}); err != nil {
+		return err
+	}
+	if err := session.SubscribeContext(context, DialerCallDataMessage{}, struct{}{}); err != nil {
+		return err
+	}
+	if err := session.SubscribeContext(context, DialerBreakGrantedMessage{}, struct{}{}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DialerLogon logs the User on the given Dialer campaigns
+//
+// The Session is also subscribed to DialerBreakGrantedMessage, if it is not already,
+// so the breaks requested with DialerRequestBreak can be seen starting (see OnDialerBreakGranted).
+// As the User is logged on already, a failure of this subscription is only logged,
+// SubscribeDialer can subscribe again later.
+func (session *Session) DialerLogon(campaigns ...DialerCampaign) error {
+	return session.DialerLogonContext(session.Context, campaigns...)
+}
+
+// DialerLogonContext logs the User on the given Dialer campaigns
+//
+// The context cancels the requests
+func (session *Session) DialerLogonContext(context context.Context, campaigns ...DialerCampaign) error {
+	if len(campaigns) == 0 {
+		return errors.ArgumentMissing.With("campaigns")
+	}
+	err := session.sendPostContext(context, "/dialer/logon", struct {
+		CampaignIDs []string `json:"campaignIds"`
+	}{CampaignIDs: IDList(campaigns)}, nil)
+	if err != nil {
+		return err
+	}
+	if _, found := session.GetSubscription(DialerBreakGrantedMessage{}.GetType()); !found {
+		if err = session.SubscribeContext(context, DialerBreakGrantedMessage{}, struct{}{}); err != nil {
+			session.Logger.Child(nil, "dialer").Errorf("Failed to subscribe to the Dialer break grants, the breaks will not be seen starting", err)
+		}
+	}
+	return nil
+}
+
+// DialerLogoff logs the User off the given Dialer campaigns, or off all campaigns if none is given
+func (session *Session) DialerLogoff(campaigns ...DialerCampaign) error {
+	return session.DialerLogoffContext(session.Context, campaigns...)
+}
+
+// DialerLogoffContext logs the User off the given Dialer campaigns, or off all campaigns if none is given
+//
+// The context cancels the request
+func (session *Session) DialerLogoffContext(context context.Context, campaigns ...DialerCampaign) error {
+	return session.sendPostContext(context, "/dialer/logoff", struct {
+		CampaignIDs []string `json:"campaignIds,omitempty"`
+	}{CampaignIDs: IDList(campaigns)}, nil)
+}
+
+// DialerRequestBreak requests a break
+//
+// The break starts when the Session receives a DialerBreakGrantedMessage
+func (session *Session) DialerRequestBreak() error {
+	return session.DialerRequestBreakContext(session.Context)
+}
+
+// DialerRequestBreakContext requests a break
+//
+// The context cancels the request
+func (session *Session) DialerRequestBreakContext(context context.Context) error {
+	return session.sendPostContext(context, "/dialer/request-break", struct{}{}, nil)
+}
+
+// DialerEndBreak ends a break
+func (session *Session) DialerEndBreak() error {
+	return session.DialerEndBreakContext(session.Context)
+}
+
+// DialerEndBreakContext ends a break
+//
+// The context cancels the request
+func (session *Session) DialerEndBreakContext(context context.Context) error {
+	return session.sendPostContext(context, "/dialer/end-break", struct{}{}, nil)
+}
+
+// DialerDispose wraps up a Dialer call
+func (session *Session) DialerDispose(disposition DialerDisposition) error {
+	return session.DialerDisposeContext(session.Context, disposition)
+}
+
+// DialerDisposeContext wraps up a Dialer call
+//
+// The context cancels the request
+func (session *Session) DialerDisposeContext(context context.Context, disposition DialerDisposition) error {
+	if len(disposition.CallIDKey) == 0 {
+		return errors.ArgumentMissing.With("callIdKey")
+	}
+	if len(disposition.WrapupCategory) == 0 && len(disposition.ReasonCode) == 0 {
+		return errors.ArgumentMissing.With("reasonCode")
+	}
+	return session.sendPostContext(context, "/dialer/disposition", disposition, nil)
+}
+
+// DialerManualCall places a manual call on a Dialer campaign
+func (session *Session) DialerManualCall(campaign DialerCampaign, phoneNumber string) (*Interaction, error) {
+	return session.DialerManualCallContext(session.Context, campaign, phoneNumber)
+}
+
+// DialerManualCallContext places a manual call on a Dialer campaign
+//
+// The context cancels the request
+func (session *Session) DialerManualCallContext(context context.Context, campaign DialerCampaign, phoneNumber string) (*Interaction, error) {
+	if len(phoneNumber) == 0 {
+		return nil, errors.ArgumentMissing.With("phoneNumber")
+	}
+	results := Interaction{}
+	err := session.sendPostContext(context, "/dialer/manual-call", struct {
+		CampaignID  string `json:"campaignId"`
+		PhoneNumber string `json:"phoneNumber"`
+	}{CampaignID: campaign.ID, PhoneNumber: phoneNumber}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+// DialerRequestPreviewCall requests the next preview call of a Dialer campaign
+//
+// The call data arrives in a DialerCallDataMessage with IsPreview set,
+// call DialerPlacePreviewCall to dial it
+func (session *Session) DialerRequestPreviewCall(campaign DialerCampaign) error {
+	return session.DialerRequestPreviewCallContext(session.Context, campaign)
+}
+
+// DialerRequestPreviewCallContext requests the next preview call of a Dialer campaign
+//
+// The context cancels the request
+func (session *Session) DialerRequestPreviewCallContext(context context.Context, campaign DialerCampaign) error {
+	return session.sendPostContext(context, "/dialer/request-call", struct {
+		CampaignID string `json:"campaignId"`
+	}{CampaignID: campaign.ID}, nil)
+}
+
+// DialerPlacePreviewCall dials a preview call
+func (session *Session) DialerPlacePreviewCall(callData DialerCallDataMessage) error {
+	return session.DialerPlacePreviewCallContext(session.Context, callData)
+}
+
+// DialerPlacePreviewCallContext dials a preview call
+//
+// The context cancels the request
+func (session *Session) DialerPlacePreviewCallContext(context context.Context, callData DialerCallDataMessage) error {
+	if len(callData.CallIDKey) == 0 {
+		return errors.ArgumentMissing.With("callIdKey")
+	}
+	return session.sendPostContext(context, "/dialer/preview-call", struct {
+		CallIDKey string `json:"callIdKey"`
+	}{CallIDKey: callData.CallIDKey}, nil)
+}
@@ -0,0 +1,72 @@
+package icws
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gildas/go-errors"
+)
+
+// DialerBreakGrantedMessage tells the break requested by the User has started
+type DialerBreakGrantedMessage struct {
+	GrantedAt Time `json:"breakGrantedDateTime"`
+}
+
+func init() {
+	messageRegistry.Add(DialerBreakGrantedMessage{})
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (message DialerBreakGrantedMessage) GetType() string {
+	return "urn:inin.com:dialer:breakGrantedMessage"
+}
+
+// Subscribe subscribe a Session to this type of messages
+//
+// implements Subscriber
+func (message DialerBreakGrantedMessage) Subscribe(context context.Context, session *Session, payload interface{}) error {
+	return session.sendPutContext(context, "/messaging/subscriptions/dialer/break-granted", payload, nil)
+}
+
+// Subscribe subscribe a Session to this type of messages
+//
+// implements Unsubscriber
+func (message DialerBreakGrantedMessage) Unsubscribe(context context.Context, session *Session) error {
+	return session.sendDeleteContext(context, "/messaging/subscriptions/dialer/break-granted")
+}
+
+// MarshalJSON marshals into JSON
+//
+// implements json.Marshaler
+func (message DialerBreakGrantedMessage) MarshalJSON() ([]byte, error) {
+	type surrogate DialerBreakGrantedMessage
+	data, err := json.Marshal(struct {
+		Type string `json:"__type"`
+		surrogate
+	}{
+		Type:      message.GetType(),
+		surrogate: surrogate(message),
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// implements json.Unmarshaler
+func (message *DialerBreakGrantedMessage) UnmarshalJSON(payload []byte) (err error) {
+	type surrogate DialerBreakGrantedMessage
+	var inner struct {
+		Type string `json:"__type"`
+		surrogate
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	if inner.Type != (DialerBreakGrantedMessage{}.GetType()) {
+		return errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("__type", inner.Type))
+	}
+	*message = DialerBreakGrantedMessage(inner.surrogate)
+	return nil
+}
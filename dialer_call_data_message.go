@@ -0,0 +1,77 @@
+package icws
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gildas/go-errors"
+)
+
+// DialerCallDataMessage gives the data of the contact of a Dialer call
+type DialerCallDataMessage struct {
+	InteractionID  string            `json:"interactionId,omitempty"`
+	CampaignID     string            `json:"campaignId"`
+	CallIDKey      string            `json:"callIdKey"`
+	IsPreview      bool              `json:"isPreview"`
+	PreviewTimeout int               `json:"previewTimeout,omitempty"` // in seconds
+	ContactColumns map[string]string `json:"contactColumns"`
+}
+
+func init() {
+	messageRegistry.Add(DialerCallDataMessage{})
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (message DialerCallDataMessage) GetType() string {
+	return "urn:inin.com:dialer:dataPopMessage"
+}
+
+// Subscribe subscribe a Session to this type of messages
+//
+// implements Subscriber
+func (message DialerCallDataMessage) Subscribe(context context.Context, session *Session, payload interface{}) error {
+	return session.sendPutContext(context, "/messaging/subscriptions/dialer/data-pop", payload, nil)
+}
+
+// Subscribe subscribe a Session to this type of messages
+//
+// implements Unsubscriber
+func (message DialerCallDataMessage) Unsubscribe(context context.Context, session *Session) error {
+	return session.sendDeleteContext(context, "/messaging/subscriptions/dialer/data-pop")
+}
+
+// MarshalJSON marshals into JSON
+//
+// implements json.Marshaler
+func (message DialerCallDataMessage) MarshalJSON() ([]byte, error) {
+	type surrogate DialerCallDataMessage
+	data, err := json.Marshal(struct {
+		Type string `json:"__type"`
+		surrogate
+	}{
+		Type:      message.GetType(),
+		surrogate: surrogate(message),
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// implements json.Unmarshaler
+func (message *DialerCallDataMessage) UnmarshalJSON(payload []byte) (err error) {
+	type surrogate DialerCallDataMessage
+	var inner struct {
+		Type string `json:"__type"`
+		surrogate
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	if inner.Type != (DialerCallDataMessage{}.GetType()) {
+		return errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("__type", inner.Type))
+	}
+	*message = DialerCallDataMessage(inner.surrogate)
+	return nil
+}
@@ -0,0 +1,77 @@
+package icws
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gildas/go-errors"
+)
+
+// DialerCampaignMessage describes the changes of the Dialer campaigns of the User
+//
+// When IsDelta is false, CampaignsAdded contains all the campaigns
+type DialerCampaignMessage struct {
+	CampaignsAdded   []DialerCampaign `json:"campaignsAdded,omitempty"`
+	CampaignsChanged []DialerCampaign `json:"campaignsChanged,omitempty"`
+	CampaignsRemoved []string         `json:"campaignsRemoved,omitempty"`
+	IsDelta          bool             `json:"isDelta"`
+}
+
+func init() {
+	messageRegistry.Add(DialerCampaignMessage{})
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (message DialerCampaignMessage) GetType() string {
+	return "urn:inin.com:dialer:campaignsMessage"
+}
+
+// Subscribe subscribe a Session to this type of messages
+//
+// implements Subscriber
+func (message DialerCampaignMessage) Subscribe(context context.Context, session *Session, payload interface{}) error {
+	return session.sendPutContext(context, "/messaging/subscriptions/dialer/campaigns", payload, nil)
+}
+
+// Subscribe subscribe a Session to this type of messages
+//
+// implements Unsubscriber
+func (message DialerCampaignMessage) Unsubscribe(context context.Context, session *Session) error {
+	return session.sendDeleteContext(context, "/messaging/subscriptions/dialer/campaigns")
+}
+
+// MarshalJSON marshals into JSON
+//
+// implements json.Marshaler
+func (message DialerCampaignMessage) MarshalJSON() ([]byte, error) {
+	type surrogate DialerCampaignMessage
+	data, err := json.Marshal(struct {
+		Type string `json:"__type"`
+		surrogate
+	}{
+		Type:      message.GetType(),
+		surrogate: surrogate(message),
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// implements json.Unmarshaler
+func (message *DialerCampaignMessage) UnmarshalJSON(payload []byte) (err error) {
+	type surrogate DialerCampaignMessage
+	var inner struct {
+		Type string `json:"__type"`
+		surrogate
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	if inner.Type != (DialerCampaignMessage{}.GetType()) {
+		return errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("__type", inner.Type))
+	}
+	*message = DialerCampaignMessage(inner.surrogate)
+	return nil
+}
@@ -0,0 +1,98 @@
+package icws_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gildas/go-icws"
+)
+
+func (suite *SessionSuite) TestCanWorkOnDialerCampaigns() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["GET /icws/1234/dialer/campaigns"] = `{"campaigns": [{"id": "c1", "name": "Renewals", "status": "Running", "dialingMode": "Preview"}]}`
+		server.Responses["POST /icws/1234/dialer/manual-call"] = `{"interactionId": "2001"}`
+	})
+
+	campaigns, err := session.GetDialerCampaigns()
+	suite.Require().Nil(err)
+	suite.Require().Len(campaigns, 1)
+	suite.Assert().Equal(icws.PreviewMode, campaigns[0].Mode)
+
+	suite.Require().Nil(session.DialerLogon(campaigns...))
+	suite.Assert().JSONEq(`{"campaignIds": ["c1"]}`, server.Payload(http.MethodPost, "/icws/1234/dialer/logon"))
+	suite.Assert().Equal(1, server.Count(http.MethodPut, "/icws/1234/messaging/subscriptions/dialer/break-granted"))
+	suite.Require().Nil(session.DialerLogon(campaigns...))
+	suite.Assert().Equal(1, server.Count(http.MethodPut, "/icws/1234/messaging/subscriptions/dialer/break-granted"), "Logging on again should not subscribe again")
+
+	interaction, err := session.DialerManualCall(campaigns[0], "+13175551234")
+	suite.Require().Nil(err)
+	suite.Assert().Equal("2001", interaction.ID)
+
+	suite.Require().Nil(session.DialerDispose(icws.DialerDisposition{InteractionID: "2001", CallIDKey: "key", WrapupCategory: icws.SuccessWrapup, ReasonCode: "Sale"}))
+	suite.Assert().JSONEq(`{"interactionId": "2001", "callIdKey": "key", "wrapupCategory": "Success", "reasonCode": "Sale"}`, server.Payload(http.MethodPost, "/icws/1234/dialer/disposition"))
+
+	suite.Require().Nil(session.DialerRequestBreak())
+	suite.Require().Nil(session.DialerEndBreak())
+	suite.Require().Nil(session.DialerLogoff())
+	suite.Assert().JSONEq(`{}`, server.Payload(http.MethodPost, "/icws/1234/dialer/logoff"))
+}
+
+func (suite *SessionSuite) TestCanReceiveDialerMessages() {
+	session, server := suite.NewConnectedSession()
+	events := session.Events()
+	suite.Require().Nil(session.SubscribeDialer())
+	suite.Assert().Equal(1, server.Count(http.MethodPut, "/icws/1234/messaging/subscriptions/dialer/campaigns"))
+	suite.Assert().Equal(1, server.Count(http.MethodPut, "/icws/1234/messaging/subscriptions/dialer/data-pop"))
+	suite.Assert().Equal(1, server.Count(http.MethodPut, "/icws/1234/messaging/subscriptions/dialer/break-granted"))
+
+	server.StreamEvents <- "data: {\"__type\": \"urn:inin.com:dialer:campaignsMessage\", \"isDelta\": true, \"campaignsChanged\": [{\"id\": \"c1\", \"status\": \"Idle\"}]}\n\n"
+	server.StreamEvents <- "data: {\"__type\": \"urn:inin.com:dialer:dataPopMessage\", \"campaignId\": \"c1\", \"callIdKey\": \"key\", \"isPreview\": true, \"contactColumns\": {\"FIRSTNAME\": \"John\"}}\n\n"
+	for _, expected := range []icws.Message{&icws.DialerCampaignMessage{}, &icws.DialerCallDataMessage{}} {
+		select {
+		case event := <-events:
+			suite.Require().IsType(expected, event.Message)
+			switch message := event.Message.(type) {
+			case *icws.DialerCampaignMessage:
+				suite.Require().Len(message.CampaignsChanged, 1)
+				suite.Assert().Equal(icws.CampaignIdle, message.CampaignsChanged[0].Status)
+			case *icws.DialerCallDataMessage:
+				suite.Assert().Equal("John", message.ContactColumns["FIRSTNAME"])
+				suite.Require().Nil(session.DialerPlacePreviewCall(*message))
+			}
+		case <-time.After(5 * time.Second):
+			suite.FailNow("Timeout while waiting for a Dialer message")
+		}
+	}
+}
+
+func (suite *SessionSuite) TestCanHandleDialerBreakGranted() {
+	session, server := suite.NewConnectedSession()
+	granted := make(chan icws.DialerBreakGrantedMessage, 1)
+	session.OnDialerBreakGranted(func(message icws.DialerBreakGrantedMessage) {
+		granted <- message
+	})
+	suite.Require().Nil(session.DialerLogon(icws.DialerCampaign{ID: "c1"}))
+	suite.Require().Nil(session.DialerRequestBreak())
+
+	server.StreamEvents <- "data: {\"__type\": \"urn:inin.com:dialer:breakGrantedMessage\", \"breakGrantedDateTime\": \"20240102T030405Z\"}\n\n"
+	select {
+	case message := <-granted:
+		suite.Assert().Equal(2024, time.Time(message.GrantedAt).Year())
+	case <-time.After(5 * time.Second):
+		suite.FailNow("Timeout while waiting for the break to be granted")
+	}
+}
+
+func (suite *SessionSuite) TestShouldLogonEvenIfBreakGrantsCannotBeSubscribed() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Handlers["PUT /icws/1234/messaging/subscriptions/dialer/break-granted"] = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	suite.Require().Nil(session.DialerLogon(icws.DialerCampaign{ID: "c1"}), "The User is logged on")
+	suite.Assert().Equal(1, server.Count(http.MethodPost, "/icws/1234/dialer/logon"))
+	suite.Assert().Equal(0, server.Count(http.MethodPost, "/icws/1234/dialer/logoff"))
+	_, found := session.GetSubscription(icws.DialerBreakGrantedMessage{}.GetType())
+	suite.Assert().False(found)
+}
@@ -0,0 +1,160 @@
+package icws
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// DefaultSlowHandlerThreshold is the default duration after which a Message handler is reported as slow
+const DefaultSlowHandlerThreshold = 5 * time.Second
+
+// dispatcher dispatches the Messages of a Session to their handlers
+type dispatcher struct {
+	handlers []*messageHandler
+	mutex    sync.Mutex
+}
+
+// messageHandler is a handler registered with On
+//
+// It gets the Messages it accepts from its own Events chan, and processes them one at a time, in order.
+type messageHandler struct {
+	index   int
+	accepts func(Message) bool
+	handle  func(Message)
+	events  chan EventSource // the Events chan being processed, if any
+}
+
+// On registers a handler for the Messages of type T
+//
+// Each handler processes its Messages one at a time, in the order they were received, in its own goroutine.
+// Its Messages are queued according to SessionOptions.EventBufferSize and SessionOptions.EventOverflow,
+// with BlockOnOverflow a slow handler slows down the processing of the PureConnect messages.
+// If a handler panics, the panic is recovered and logged.
+// If a handler takes longer than SessionOptions.SlowHandlerThreshold, it is reported in the logs.
+func On[T Message](session *Session, handler func(T)) {
+	session.dispatcher.mutex.Lock()
+	session.dispatcher.handlers = append(session.dispatcher.handlers, &messageHandler{
+		index: len(session.dispatcher.handlers),
+		accepts: func(message Message) bool {
+			_, ok := asMessage[T](message)
+			return ok
+		},
+		handle: func(message Message) {
+			if typed, ok := asMessage[T](message); ok {
+				handler(typed)
+			}
+		},
+	})
+	session.dispatcher.mutex.Unlock()
+	if session.IsConnected() {
+		session.startDispatching()
+	}
+}
+
+// OnUserStatus registers a handler for UserStatusMessage
+func (session *Session) OnUserStatus(handler func(UserStatusMessage)) {
+	On(session, handler)
+}
+
+// OnLicense registers a handler for LicenseMessage
+func (session *Session) OnLicense(handler func(LicenseMessage)) {
+	On(session, handler)
+}
+
+// OnStatusMessages registers a handler for StatusMessageMessage
+func (session *Session) OnStatusMessages(handler func(StatusMessageMessage)) {
+	On(session, handler)
+}
+
+// OnDialerCampaigns registers a handler for DialerCampaignMessage
+func (session *Session) OnDialerCampaigns(handler func(DialerCampaignMessage)) {
+	On(session, handler)
+}
+
+// OnDialerCallData registers a handler for DialerCallDataMessage
+func (session *Session) OnDialerCallData(handler func(DialerCallDataMessage)) {
+	On(session, handler)
+}
+
+// OnDialerBreakGranted registers a handler for DialerBreakGrantedMessage
+func (session *Session) OnDialerBreakGranted(handler func(DialerBreakGrantedMessage)) {
+	On(session, handler)
+}
+
+// startDispatching starts the handlers that are not running yet
+func (session *Session) startDispatching() {
+	session.dispatcher.mutex.Lock()
+	defer session.dispatcher.mutex.Unlock()
+	for _, handler := range session.dispatcher.handlers {
+		if handler.events != nil {
+			continue
+		}
+		events := session.EventsWithOptions(EventsOptions{
+			BufferSize: session.EventBufferSize,
+			Overflow:   session.EventOverflow,
+			filter:     handler.accepts,
+		})
+		handler.events = events
+		go func(handler *messageHandler) {
+			for event := range events {
+				session.dispatch(handler, event)
+			}
+			session.dispatcher.mutex.Lock()
+			if handler.events == events {
+				handler.events = nil
+			}
+			session.dispatcher.mutex.Unlock()
+		}(handler)
+	}
+}
+
+// stopDispatching stops the handlers, so dispatching can start again
+func (session *Session) stopDispatching() {
+	session.dispatcher.mutex.Lock()
+	running := []chan EventSource{}
+	for _, handler := range session.dispatcher.handlers {
+		if handler.events != nil {
+			running = append(running, handler.events)
+			handler.events = nil
+		}
+	}
+	session.dispatcher.mutex.Unlock()
+	for _, events := range running {
+		session.ReleaseEvents(events)
+	}
+}
+
+// dispatch gives the Message of the EventSource to the handler
+func (session *Session) dispatch(handler *messageHandler, event EventSource) {
+	log := session.Logger.Child(nil, "dispatch", "handler", handler.index, "type", event.Message.GetType())
+	threshold := session.SlowHandlerThreshold
+	start := time.Now()
+	timer := time.AfterFunc(threshold, func() {
+		log.Warnf("Handler #%d is slow, still running after %s", handler.index, threshold)
+	})
+	defer func() {
+		timer.Stop()
+		if recovered := recover(); recovered != nil {
+			log.Errorf("Handler #%d panicked: %v", handler.index, recovered)
+		}
+	}()
+	handler.handle(event.Message)
+	log.Tracef("Handler #%d processed the message in %s", handler.index, time.Since(start))
+}
+
+// asMessage converts a Message to the type T
+//
+// Messages unmarshaled from PureConnect are pointers, they are converted as well
+func asMessage[T Message](message Message) (T, bool) {
+	if typed, ok := message.(T); ok {
+		return typed, true
+	}
+	if value := reflect.ValueOf(message); value.Kind() == reflect.Ptr && !value.IsNil() {
+		if typed, ok := value.Elem().Interface().(T); ok {
+			return typed, true
+		}
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,228 @@
+package icws
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// EventOverflowPolicy tells what to do when the buffer of an Events chan is full
+type EventOverflowPolicy uint32
+
+const (
+	// BlockOnOverflow waits for the application to read the Events chan
+	BlockOnOverflow EventOverflowPolicy = iota
+	// DropOldestOnOverflow drops the oldest EventSource of the Events chan
+	DropOldestOnOverflow
+	// DropNewestOnOverflow drops the EventSource that does not fit in the Events chan
+	DropNewestOnOverflow
+)
+
+// DefaultEventBufferSize is the default size of the buffer of Events chans
+const DefaultEventBufferSize = 64
+
+// EventsOptions describes the options of an Events chan
+type EventsOptions struct {
+	BufferSize int                 // Default: DefaultEventBufferSize
+	Overflow   EventOverflowPolicy // Default: BlockOnOverflow
+	filter     func(Message) bool  // if set, only the accepted Messages are sent to the Events chan
+}
+
+// EventBroker fans out EventSource to all its subscribers
+//
+// Each subscriber gets its own buffered chan.
+type EventBroker struct {
+	subscribers map[chan EventSource]*eventSubscriber
+	dropped     uint64
+	mutex       sync.RWMutex
+}
+
+type eventSubscriber struct {
+	events    chan EventSource
+	overflow  EventOverflowPolicy
+	filter    func(Message) bool
+	dropped   uint64
+	done      chan struct{} // closed when the subscriber leaves
+	leaveOnce sync.Once
+	closed    bool         // true once events is closed
+	mutex     sync.RWMutex // held for reading while sending, for writing while closing events
+}
+
+// NewEventBroker creates a new EventBroker
+func NewEventBroker() *EventBroker {
+	return &EventBroker{
+		subscribers: map[chan EventSource]*eventSubscriber{},
+	}
+}
+
+// Subscribe gives a new Events chan that receives all EventSource published from now on
+//
+// Call Unsubscribe when the chan is not needed anymore.
+func (broker *EventBroker) Subscribe(options EventsOptions) chan EventSource {
+	if options.BufferSize <= 0 {
+		options.BufferSize = DefaultEventBufferSize
+	}
+	subscriber := &eventSubscriber{
+		events:   make(chan EventSource, options.BufferSize),
+		overflow: options.Overflow,
+		filter:   options.filter,
+		done:     make(chan struct{}),
+	}
+	broker.mutex.Lock()
+	broker.subscribers[subscriber.events] = subscriber
+	broker.mutex.Unlock()
+	return subscriber.events
+}
+
+// Unsubscribe closes the given Events chan, it does not receive EventSource anymore
+func (broker *EventBroker) Unsubscribe(events chan EventSource) {
+	broker.mutex.Lock()
+	subscriber, found := broker.subscribers[events]
+	delete(broker.subscribers, events)
+	broker.mutex.Unlock()
+	if found {
+		subscriber.close()
+	}
+}
+
+// isSubscribed tells if the given Events chan still receives EventSource
+func (broker *EventBroker) isSubscribed(events chan EventSource) bool {
+	broker.mutex.RLock()
+	defer broker.mutex.RUnlock()
+	_, found := broker.subscribers[events]
+	return found
+}
+
+// Publish sends the EventSource to all subscribers, according to their EventOverflowPolicy
+//
+// The subscribers are sent to without holding the lock of the EventBroker,
+// so a blocked subscriber can still subscribe or unsubscribe Events chans.
+//
+// returns false if the context was canceled
+func (broker *EventBroker) Publish(ctx context.Context, event EventSource) bool {
+	broker.mutex.RLock()
+	subscribers := make([]*eventSubscriber, 0, len(broker.subscribers))
+	for _, subscriber := range broker.subscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+	broker.mutex.RUnlock()
+
+	for _, subscriber := range subscribers {
+		if subscriber.filter != nil && !subscriber.filter(event.Message) {
+			continue
+		}
+		if !subscriber.send(ctx, event) {
+			atomic.AddUint64(&subscriber.dropped, 1)
+			atomic.AddUint64(&broker.dropped, 1)
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Close closes all the Events chans
+//
+// The EventBroker can still be used, new subscribers get new Events chans
+func (broker *EventBroker) Close() {
+	broker.mutex.Lock()
+	subscribers := broker.subscribers
+	broker.subscribers = map[chan EventSource]*eventSubscriber{}
+	broker.mutex.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber.close()
+	}
+}
+
+// Dropped tells how many EventSource were dropped for the given Events chan
+func (broker *EventBroker) Dropped(events chan EventSource) uint64 {
+	broker.mutex.RLock()
+	defer broker.mutex.RUnlock()
+	if subscriber, found := broker.subscribers[events]; found {
+		return atomic.LoadUint64(&subscriber.dropped)
+	}
+	return 0
+}
+
+// TotalDropped tells how many EventSource were dropped for all subscribers since the EventBroker was created
+func (broker *EventBroker) TotalDropped() uint64 {
+	return atomic.LoadUint64(&broker.dropped)
+}
+
+// send sends the EventSource to the subscriber
+//
+// returns false if the EventSource or an older one was dropped
+func (subscriber *eventSubscriber) send(ctx context.Context, event EventSource) bool {
+	subscriber.mutex.RLock()
+	defer subscriber.mutex.RUnlock()
+	if subscriber.closed {
+		return false
+	}
+	switch subscriber.overflow {
+	case DropNewestOnOverflow:
+		select {
+		case subscriber.events <- event:
+			return true
+		default:
+			return false
+		}
+	case DropOldestOnOverflow:
+		select {
+		case subscriber.events <- event:
+			return true
+		default:
+		}
+		select {
+		case <-subscriber.events: // drops the oldest
+		default:
+		}
+		select {
+		case subscriber.events <- event:
+		default:
+		}
+		return false
+	default:
+		select {
+		case subscriber.events <- event:
+			return true
+		case <-subscriber.done:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// leave tells the publishers to not wait for this subscriber anymore
+func (subscriber *eventSubscriber) leave() {
+	subscriber.leaveOnce.Do(func() { close(subscriber.done) })
+}
+
+// close closes the Events chan of the subscriber once no publisher is sending to it
+func (subscriber *eventSubscriber) close() {
+	subscriber.leave() // unblocks the publishers waiting on this subscriber
+	subscriber.mutex.Lock()
+	defer subscriber.mutex.Unlock()
+	if !subscriber.closed {
+		subscriber.closed = true
+		close(subscriber.events)
+	}
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (policy EventOverflowPolicy) String() string {
+	switch policy {
+	case BlockOnOverflow:
+		return "block"
+	case DropOldestOnOverflow:
+		return "drop oldest"
+	case DropNewestOnOverflow:
+		return "drop newest"
+	default:
+		return "unknown"
+	}
+}
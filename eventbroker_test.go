@@ -0,0 +1,47 @@
+package icws_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-icws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldNotLockBrokerWhilePublishing(t *testing.T) {
+	broker := icws.NewEventBroker()
+	blocked := broker.Subscribe(icws.EventsOptions{BufferSize: 1})
+	require.True(t, broker.Publish(context.Background(), icws.EventSource{ID: "1"}))
+
+	published := make(chan bool, 1)
+	go func() {
+		published <- broker.Publish(context.Background(), icws.EventSource{ID: "2"})
+	}()
+	time.Sleep(50 * time.Millisecond) // gives Publish the time to block on the full Events chan
+
+	subscribed := make(chan chan icws.EventSource, 1)
+	go func() {
+		subscribed <- broker.Subscribe(icws.EventsOptions{})
+	}()
+	select {
+	case events := <-subscribed:
+		broker.Unsubscribe(events)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "Subscribe should not wait for a blocked subscriber")
+	}
+
+	broker.Unsubscribe(blocked)
+	select {
+	case ok := <-published:
+		assert.True(t, ok)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "Publish should stop waiting for a subscriber that left")
+	}
+	assert.Equal(t, uint64(1), broker.TotalDropped())
+	_, ok := <-blocked
+	assert.True(t, ok, "The buffered EventSource should still be readable")
+	_, ok = <-blocked
+	assert.False(t, ok, "The Events chan should be closed")
+}
@@ -0,0 +1,94 @@
+package icws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gildas/go-errors"
+)
+
+// DefaultMessagePollingInterval is the default interval between 2 polls of the PureConnect messaging service
+const DefaultMessagePollingInterval = 1 * time.Second
+
+// Poll polls the PureConnect messaging service of the Session at the given interval
+//
+// This is used when the PureConnect Server does not support Server-Sent Events (messaging version 2).
+// The messages are published to the Broker, just like with Connect.
+//
+// Do not forget to call Disconnect when you are done
+func (stream *EventStream) Poll(session *Session, path string, interval time.Duration) error {
+	if stream.Logger == nil {
+		stream.Logger = session.Logger.Child("stream", stream)
+	}
+	log := stream.Logger.Child(nil, "messagepolling")
+
+	if interval <= 0 {
+		interval = DefaultMessagePollingInterval
+	}
+
+	parent := session.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	done := stream.start(cancel)
+
+	// The Message poller
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			messages, err := stream.poll(ctx, session, path)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if errors.Is(err, errors.HTTPUnauthorized) || errors.Is(err, errors.HTTPNotFound) {
+					log.Errorf("The PureConnect session is gone, stopping polling", err)
+					if !stream.keepOpen {
+						stream.Broker.Close()
+					}
+					return
+				}
+				log.Warnf("Failed to poll messages: %s", err.Error())
+			}
+			for _, message := range messages {
+				// publish the EventSource for processing by the application
+				if !stream.publish(ctx, NewEventSource(message)) {
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// poll gets the pending messages from the PureConnect messaging service
+func (stream *EventStream) poll(ctx context.Context, session *Session, path string) ([]Message, error) {
+	log := stream.Logger.Child(nil, "poll")
+
+	payloads := []json.RawMessage{}
+	if _, err := session.sendRequest(ctx, http.MethodGet, path, nil, nil, nil, &payloads); err != nil {
+		return []Message{}, err
+	}
+	messages := make([]Message, 0, len(payloads))
+	for _, payload := range payloads {
+		log.Debugf("Unmarshaling: %s", string(payload))
+		message, err := UnmarshalMessage(payload)
+		if err != nil {
+			log.Errorf("Unknown Message: %s", string(payload), err)
+			continue
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
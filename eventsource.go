@@ -2,9 +2,13 @@ package icws
 
 import (
 	"bufio"
+	"context"
+	"math/rand"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gildas/go-core"
@@ -19,132 +23,398 @@ type EventSource struct {
 	Message Message `json:"message"`
 }
 
+// NewEventSource creates a new EventSource for the given Message
+func NewEventSource(message Message) EventSource {
+	return EventSource{
+		Type:    message.GetType(),
+		Message: message,
+	}
+}
+
 // EventStream describes an EventSource processor
+//
+// When the connection to PureConnect ends unexpectedly, the EventStream reconnects
+// after the retry delay given by the server (or ReconnectDelay), with an exponential
+// backoff capped at MaxReconnectDelay and some jitter.
+// The application is notified via an EventStreamDisconnectedMessage and an
+// EventStreamReconnectedMessage as events might have been lost.
+//
+// The EventSource are sent to all the subscribers of the Broker.
 type EventStream struct {
-	Events    chan EventSource // listen to this to process EventSource
-	closeChan chan struct{}
-	Logger    *logger.Logger
+	Broker               *EventBroker // subscribe to this to process EventSource
+	Logger               *logger.Logger
+	ReconnectDelay       time.Duration // Initial delay before reconnecting, the server can change it with the "retry" field
+	MaxReconnectDelay    time.Duration // Maximum delay between 2 reconnection attempts
+	MaxReconnectAttempts int           // Maximum number of reconnection attempts, 0 means no limit
+	DisableReconnect     bool          // if true, the EventStream does not reconnect
+	keepOpen             bool          // if true, the Broker is not closed when the connection ends on its own
+	lastEventID          string
+	cancel               context.CancelFunc // cancels the current connection
+	done                 chan struct{}      // closed when the current connection is processed
+	mutex                sync.Mutex
 }
 
+// DefaultEventStreamReconnectDelay is the default delay before reconnecting an EventStream
+const DefaultEventStreamReconnectDelay = 3 * time.Second
+
+// DefaultEventStreamMaxReconnectDelay is the default maximum delay between 2 reconnection attempts of an EventStream
+const DefaultEventStreamMaxReconnectDelay = 1 * time.Minute
+
 // NewEventStream creates a new EventStream
 func NewEventStream() *EventStream {
 	return &EventStream{
-		Events:    make(chan EventSource),
-		closeChan: make(chan struct{}),
+		Broker:            NewEventBroker(),
+		ReconnectDelay:    DefaultEventStreamReconnectDelay,
+		MaxReconnectDelay: DefaultEventStreamMaxReconnectDelay,
 	}
 }
 
 // Connect connects to the PureConnect Server-Sent Event Service of the Session
 //
-// Do not forget to call the closeEventStream when you are done
+// Do not forget to call Disconnect when you are done
 func (stream *EventStream) Connect(session *Session, path string) error {
+	return stream.ConnectContext(session.Context, session, path)
+}
+
+// ConnectContext connects to the PureConnect Server-Sent Event Service of the Session
+//
+// The connectContext cancels the connection request only,
+// once connected the EventStream lives with the Context of the Session.
+//
+// Do not forget to call Disconnect when you are done
+func (stream *EventStream) ConnectContext(connectContext context.Context, session *Session, path string) error {
 	if stream.Logger == nil {
 		stream.Logger = session.Logger.Child("stream", stream)
 	}
 	log := stream.Logger.Child(nil, "messageprocessing")
 
-	endpoint, err := session.endpoint("/messaging/messages")
+	parent := session.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+
+	stream.mutex.Lock()
+	stream.lastEventID = ""
+	stream.mutex.Unlock()
+
+	stopWatching := cancelWhenDone(connectContext, cancel)
+	res, err := stream.connect(ctx, session, path)
+	if stopWatching() {
+		if err == nil {
+			res.Body.Close()
+		}
+		return errors.WithStack(connectContext.Err())
+	}
 	if err != nil {
+		cancel()
 		return err
 	}
 
+	done := stream.start(cancel)
+
+	// The EventSource processor
+	go func() {
+		defer close(done)
+		for {
+			if !stream.process(ctx, log, res) {
+				return // the stream was stopped
+			}
+			if stream.DisableReconnect {
+				if !stream.keepOpen {
+					stream.Broker.Close()
+				}
+				return
+			}
+			disconnectedAt := time.Now()
+			log.Warnf("Lost connection to %s, reconnecting", path)
+			if !stream.publish(ctx, NewEventSource(EventStreamDisconnectedMessage{
+				LastEventID:    stream.getLastEventID(),
+				DisconnectedAt: disconnectedAt,
+			})) {
+				return
+			}
+			var attempts int
+			if res, attempts, err = stream.reconnect(ctx, log, session, path); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Errorf("Giving up reconnecting to %s", path, err)
+				if !stream.keepOpen {
+					stream.Broker.Close()
+				}
+				return
+			}
+			if !stream.publish(ctx, NewEventSource(EventStreamReconnectedMessage{
+				LastEventID:    stream.getLastEventID(),
+				Attempts:       attempts,
+				DisconnectedAt: disconnectedAt,
+				ReconnectedAt:  time.Now(),
+			})) {
+				res.Body.Close()
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// connect sends the HTTP request to the PureConnect Server-Sent Event Service
+func (stream *EventStream) connect(ctx context.Context, session *Session, path string) (*http.Response, error) {
+	log := stream.Logger.Child(nil, "connect")
+
+	endpoint, err := session.endpoint(path)
+	if err != nil {
+		return nil, err
+	}
+
 	log.Tracef("HTTP %s %s", http.MethodGet, endpoint.String())
-	req, err := http.NewRequest(http.MethodGet, endpoint.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
 	if err != nil {
-		return errors.WithStack(err)
+		return nil, errors.WithStack(err)
 	}
 
 	req.Header.Set("UserAgent", "GENESYS ICWS GO Client v"+VERSION)
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Accept-Language", session.Language)
 	req.Header.Set("Use-Credentials", "include") // Equivalent to JavaScript: EventSource(url, { withCredentials: true })
-	if len(session.Token) > 0 {
-		req.Header.Set("ININ-ICWS-CSRF-Token", session.Token)
+	token, cookies := session.getCredentials()
+	if len(token) > 0 {
+		req.Header.Set("ININ-ICWS-CSRF-Token", token)
+	}
+	if lastEventID := stream.getLastEventID(); len(lastEventID) > 0 {
+		req.Header.Set("Last-Event-ID", lastEventID)
 	}
-	for _, cookie := range session.Cookies {
+	for _, cookie := range cookies {
 		req.AddCookie(cookie)
 	}
 	req.Close = true
 
 	log.Tracef("Request Headers: %#v", req.Header)
 	start := time.Now()
-	res, err := http.DefaultClient.Do(req)
+	res, err := session.streamClient().Do(req)
 	duration := time.Since(start)
 	if err != nil {
-		return errors.WithStack(err)
+		return nil, errors.WithStack(err)
 	}
 	log.Tracef("Response %s in %s", res.Status, duration)
 	log.Tracef("Response Headers: %#v", res.Header)
+	if res.StatusCode >= 400 {
+		res.Body.Close()
+		return nil, errors.FromHTTPStatusCode(res.StatusCode)
+	}
+	return res, nil
+}
 
-	// The EventSource processor
-	go func() {
-		defer close(stream.Events)
+// reconnect reconnects to the PureConnect Server-Sent Event Service
+//
+// The delay between attempts grows exponentially with some jitter.
+// It gives up when the PureConnect session is gone or after MaxReconnectAttempts.
+func (stream *EventStream) reconnect(ctx context.Context, log *logger.Logger, session *Session, path string) (*http.Response, int, error) {
+	for attempt := 1; stream.MaxReconnectAttempts == 0 || attempt <= stream.MaxReconnectAttempts; attempt++ {
+		delay := stream.backoff(attempt)
+		log.Infof("Reconnecting in %s (attempt #%d)", delay, attempt)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, attempt, ctx.Err()
+		}
+		res, err := stream.connect(ctx, session, path)
+		if err == nil {
+			log.Infof("Reconnected to %s after %d attempts", path, attempt)
+			return res, attempt, nil
+		}
+		if ctx.Err() != nil {
+			return nil, attempt, ctx.Err()
+		}
+		if errors.Is(err, errors.HTTPUnauthorized) || errors.Is(err, errors.HTTPNotFound) {
+			return nil, attempt, err // The PureConnect session does not exist anymore
+		}
+		log.Warnf("Failed to reconnect: %s", err.Error())
+	}
+	return nil, stream.MaxReconnectAttempts, errors.TooManyErrors.WithStack()
+}
 
-		// Closes the response's body when the stream closes
-		go func() {
-			<-stream.closeChan
-			res.Body.Close()
-		}()
+// backoff computes the delay before the given reconnection attempt
+func (stream *EventStream) backoff(attempt int) time.Duration {
+	stream.mutex.Lock()
+	delay, maxDelay := stream.ReconnectDelay, stream.MaxReconnectDelay
+	stream.mutex.Unlock()
+
+	if delay <= 0 {
+		delay = DefaultEventStreamReconnectDelay
+	}
+	if maxDelay < delay {
+		maxDelay = delay
+	}
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	// Jitter: wait between 50% and 100% of the delay
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// process reads the EventSource from the HTTP response and publishes them
+//
+// returns false if the EventStream was stopped
+func (stream *EventStream) process(ctx context.Context, log *logger.Logger, res *http.Response) bool {
+	defer res.Body.Close()
 
-		scanner := bufio.NewScanner(res.Body)
+	scanner := bufio.NewScanner(res.Body)
 
-		event := EventSource{}
-		data := strings.Builder{}
+	event := EventSource{}
+	data := strings.Builder{}
 
-		for scanner.Scan() {
-			line := scanner.Text()
-			if len(line) == 0 {
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) == 0 {
+			if data.Len() > 0 {
 				log.Debugf("Unmarshaling: %s", data.String())
-				event.Message, err = UnmarshalMessage([]byte(data.String()))
+				message, err := UnmarshalMessage([]byte(data.String()))
 				if err != nil {
 					log.Errorf("Unknown Message: %s", data.String(), err)
-					continue
+				} else {
+					event.Message = message
+					// publish the EventSource for processing by the application
+					if !stream.publish(ctx, event) {
+						return false
+					}
 				}
-				// send the EventSource to the chan for processing by the application
-				stream.Events <- event
-				event = EventSource{} // Create a new EventSource to fill in
-				data = strings.Builder{}
 			}
+			event = EventSource{} // Create a new EventSource to fill in
+			data = strings.Builder{}
+			continue
+		}
 
-			field, value := stream.analyzeLine(line)
-			switch field {
-			// See: https://developer.mozilla.org/en-US/docs/Web/API/Server-sent_events/Using_server-sent_events#event_stream_format
-			case "comment":
-				log.Tracef("Comment: %s", value)
-			case "ping":
-				if core.GetEnvAsBool("TRACE_PING", false) {
-					log.Tracef("Received a ping")
-				}
-			case "id":
-				event.ID = value
-			case "event":
-				event.Type = value
-			case "data":
-				data.WriteString(value)
-			case "retry":
-				log.Warnf("Not Implemented, Event Retry: %s", value)
-				// This is the timeout in ms to use when reconnecting to PureConnect's SSE if the connection gets closed
-			default:
-				if len(value) > 0 {
-					log.Tracef("Ignoring %s: %#+v", field, value)
-				}
+		field, value := stream.analyzeLine(line)
+		switch field {
+		// See: https://developer.mozilla.org/en-US/docs/Web/API/Server-sent_events/Using_server-sent_events#event_stream_format
+		case "comment":
+			log.Tracef("Comment: %s", value)
+		case "ping":
+			if core.GetEnvAsBool("TRACE_PING", false) {
+				log.Tracef("Received a ping")
+			}
+		case "id":
+			event.ID = value
+		case "event":
+			event.Type = value
+		case "data":
+			data.WriteString(value)
+		case "retry":
+			// This is the timeout in ms to use when reconnecting to PureConnect's SSE if the connection gets closed
+			if retry, err := strconv.Atoi(value); err == nil && retry > 0 {
+				log.Debugf("Reconnection delay is now %dms", retry)
+				stream.mutex.Lock()
+				stream.ReconnectDelay = time.Duration(retry) * time.Millisecond
+				stream.mutex.Unlock()
+			} else {
+				log.Warnf("Invalid Event Retry: %s", value)
+			}
+		default:
+			if len(value) > 0 {
+				log.Tracef("Ignoring %s: %#+v", field, value)
 			}
 		}
-		if err != nil && errors.Is(scanner.Err(), net.ErrClosed) {
-			log.Errorf("Failed to scan", scanner.Err())
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, net.ErrClosed) {
+		log.Errorf("Failed to scan", err)
+	}
+	return true
+}
+
+// publish publishes an EventSource to the subscribers of the Broker
+//
+// returns false if the EventStream was stopped
+func (stream *EventStream) publish(ctx context.Context, event EventSource) bool {
+	if !stream.Broker.Publish(ctx, event) {
+		return false
+	}
+	if len(event.ID) > 0 {
+		stream.mutex.Lock()
+		stream.lastEventID = event.ID
+		stream.mutex.Unlock()
+	}
+	return true
+}
+
+// notify publishes a Message to the subscribers of the Broker
+//
+// returns false if the Message could not be published
+func (stream *EventStream) notify(ctx context.Context, message Message) bool {
+	return stream.publish(ctx, NewEventSource(message))
+}
+
+// getLastEventID tells the ID of the last EventSource sent to the application
+func (stream *EventStream) getLastEventID() string {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+	return stream.lastEventID
+}
+
+// Disconnect disconnects the EventStream
+//
+// The Events chans of all subscribers are closed
+func (stream *EventStream) Disconnect() {
+	stream.stop()
+	stream.Broker.Close()
+}
+
+// cancelWhenDone calls cancel if the context is done before the returned stop func is called
+//
+// stop tells if cancel was called.
+func cancelWhenDone(ctx context.Context, cancel context.CancelFunc) (stop func() bool) {
+	if ctx == nil || ctx.Done() == nil {
+		return func() bool { return false }
+	}
+	stopped := make(chan struct{})
+	canceled := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+			canceled <- true
+		case <-stopped:
+			canceled <- false
 		}
-		res.Body.Close()
 	}()
+	return func() bool {
+		close(stopped)
+		return <-canceled
+	}
+}
 
-	return nil
+// start registers the cancel func of a new connection
+//
+// returns the chan to close when the connection is processed
+func (stream *EventStream) start(cancel context.CancelFunc) (done chan struct{}) {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+	stream.cancel = cancel
+	stream.done = make(chan struct{})
+	return stream.done
 }
 
-// Disconnect disconnects the EventStream
-func (stream EventStream) Disconnect() {
-	stream.closeChan <- struct{}{}
+// stop stops processing the current connection, if any, without closing the Events chan
+func (stream *EventStream) stop() {
+	stream.mutex.Lock()
+	cancel, done := stream.cancel, stream.done
+	stream.cancel, stream.done = nil, nil
+	stream.mutex.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
 }
 
-func (stream EventStream) analyzeLine(line string) (field string, value string) {
+func (stream *EventStream) analyzeLine(line string) (field string, value string) {
 	if string(line) == ":ping" {
 		return "ping", ""
 	}
@@ -0,0 +1,50 @@
+package icws
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventStreamDisconnectedMessage is sent by the EventStream when it loses its connection to PureConnect
+//
+// Events sent by PureConnect until the EventStream is reconnected might be lost
+type EventStreamDisconnectedMessage struct {
+	LastEventID    string    `json:"lastEventId"`
+	DisconnectedAt time.Time `json:"disconnectedAt"`
+}
+
+// EventStreamReconnectedMessage is sent by the EventStream when it is reconnected to PureConnect
+type EventStreamReconnectedMessage struct {
+	LastEventID    string    `json:"lastEventId"`
+	Attempts       int       `json:"attempts"`
+	DisconnectedAt time.Time `json:"disconnectedAt"`
+	ReconnectedAt  time.Time `json:"reconnectedAt"`
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (message EventStreamDisconnectedMessage) GetType() string {
+	return "urn:gildas:icws:eventStreamDisconnectedMessage"
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (message EventStreamDisconnectedMessage) String() string {
+	return fmt.Sprintf("EventStream disconnected at %s, last event: %s", message.DisconnectedAt.Format(time.RFC3339), message.LastEventID)
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (message EventStreamReconnectedMessage) GetType() string {
+	return "urn:gildas:icws:eventStreamReconnectedMessage"
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (message EventStreamReconnectedMessage) String() string {
+	return fmt.Sprintf("EventStream reconnected after %s (%d attempts), last event: %s", message.ReconnectedAt.Sub(message.DisconnectedAt), message.Attempts, message.LastEventID)
+}
@@ -0,0 +1,432 @@
+package icws
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interaction describes a PureConnect Interaction
+type Interaction struct {
+	ID         string                `json:"interactionId"`
+	Attributes InteractionAttributes `json:"attributes,omitempty"`
+}
+
+// InteractionAttributes contains the attributes of an Interaction
+//
+// PureConnect sends all attribute values as strings
+type InteractionAttributes map[string]string
+
+// InteractionState describes the state of an Interaction (attribute Eic_State)
+type InteractionState string
+
+const (
+	AlertingState           InteractionState = "A"
+	ConnectedState          InteractionState = "C"
+	HeldState               InteractionState = "H"
+	MessagingState          InteractionState = "M"
+	OfferingState           InteractionState = "O"
+	ParkedState             InteractionState = "P"
+	ProceedingState         InteractionState = "R"
+	SystemState             InteractionState = "S"
+	InternalDisconnectState InteractionState = "I"
+	ExternalDisconnectState InteractionState = "E"
+	InitializingState       InteractionState = "N"
+	DialingState            InteractionState = "D"
+	OnHoldSuspendedState    InteractionState = "X"
+	UnknownInteractionState InteractionState = ""
+)
+
+// InteractionDirection describes the direction of an Interaction (attribute Eic_CallDirection)
+type InteractionDirection string
+
+const (
+	IncomingDirection InteractionDirection = "I"
+	OutgoingDirection InteractionDirection = "O"
+)
+
+// Well-known Interaction attributes
+const (
+	StateAttribute         = "Eic_State"
+	DirectionAttribute     = "Eic_CallDirection"
+	RemoteNameAttribute    = "Eic_RemoteName"
+	RemoteAddressAttribute = "Eic_RemoteAddress"
+	ObjectTypeAttribute    = "Eic_ObjectType"
+	WorkgroupAttribute     = "Eic_WorkgroupName"
+	UserNameAttribute      = "Eic_UserName"
+	MutedAttribute         = "Eic_Muted"
+	RecordingAttribute     = "Eic_Recorders"
+	InitiationAttribute    = "Eic_InitiationTime"
+	ConnectedAtAttribute   = "Eic_ConnectTime"
+)
+
+// MakeCallOptions describes the options to make a call
+type MakeCallOptions struct {
+	Workgroup            string            `json:"workgroup,omitempty"`
+	AccountCodeID        string            `json:"accountCodeId,omitempty"`
+	AdditionalAttributes map[string]string `json:"-"`
+}
+
+// ConsultTransfer describes a consult transfer in progress
+type ConsultTransfer struct {
+	ID                   string `json:"consultTransferId"`
+	InteractionID        string `json:"interactionId"`
+	ConsultInteractionID string `json:"consultInteractionId"`
+}
+
+type interactionParameter struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// GetID tells the ID
+//
+// implements Identifiable
+func (interaction Interaction) GetID() string {
+	return interaction.ID
+}
+
+// State tells the state of the Interaction
+func (interaction Interaction) State() InteractionState {
+	return InteractionState(interaction.Attributes.GetString(StateAttribute))
+}
+
+// Direction tells the direction of the Interaction
+func (interaction Interaction) Direction() InteractionDirection {
+	return InteractionDirection(interaction.Attributes.GetString(DirectionAttribute))
+}
+
+// RemoteName tells the name of the remote party of the Interaction
+func (interaction Interaction) RemoteName() string {
+	return interaction.Attributes.GetString(RemoteNameAttribute)
+}
+
+// RemoteAddress tells the address (phone number, email, etc) of the remote party of the Interaction
+func (interaction Interaction) RemoteAddress() string {
+	return interaction.Attributes.GetString(RemoteAddressAttribute)
+}
+
+// Workgroup tells the workgroup of the Interaction
+func (interaction Interaction) Workgroup() string {
+	return interaction.Attributes.GetString(WorkgroupAttribute)
+}
+
+// IsConnected tells if the Interaction is connected
+func (interaction Interaction) IsConnected() bool {
+	return interaction.State() == ConnectedState
+}
+
+// IsHeld tells if the Interaction is held
+func (interaction Interaction) IsHeld() bool {
+	return interaction.State() == HeldState
+}
+
+// IsDisconnected tells if the Interaction is disconnected
+func (interaction Interaction) IsDisconnected() bool {
+	state := interaction.State()
+	return state == InternalDisconnectState || state == ExternalDisconnectState
+}
+
+// IsMuted tells if the Interaction is muted
+func (interaction Interaction) IsMuted() bool {
+	return interaction.Attributes.GetBool(MutedAttribute)
+}
+
+// IsRecording tells if the Interaction is recorded
+func (interaction Interaction) IsRecording() bool {
+	return len(interaction.Attributes.GetString(RecordingAttribute)) > 0
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (interaction Interaction) String() string {
+	if remoteName := interaction.RemoteName(); len(remoteName) > 0 {
+		return interaction.ID + " (" + remoteName + ")"
+	}
+	return interaction.ID
+}
+
+// GetString gets the value of an attribute
+func (attributes InteractionAttributes) GetString(name string) string {
+	return attributes[name]
+}
+
+// GetInt gets the value of an attribute as an int
+func (attributes InteractionAttributes) GetInt(name string) (int, bool) {
+	value, err := strconv.Atoi(attributes[name])
+	return value, err == nil
+}
+
+// GetBool gets the value of an attribute as a bool
+//
+// PureConnect uses "1" and "0" for booleans
+func (attributes InteractionAttributes) GetBool(name string) bool {
+	switch strings.ToLower(attributes[name]) {
+	case "1", "true", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// GetTime gets the value of an attribute as a time.Time
+func (attributes InteractionAttributes) GetTime(name string) (time.Time, bool) {
+	value := attributes[name]
+	for _, layout := range []string{"20060102T150405Z", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// MakeCall places a call to the given target
+func (session *Session) MakeCall(target string, options MakeCallOptions) (*Interaction, error) {
+	return session.MakeCallContext(session.Context, target, options)
+}
+
+// MakeCallContext places a call to the given target
+//
+// The context cancels the request
+func (session *Session) MakeCallContext(context context.Context, target string, options MakeCallOptions) (*Interaction, error) {
+	results := Interaction{}
+	err := session.sendPostContext(context, "/interactions", struct {
+		Type                 string                 `json:"__type"`
+		Target               string                 `json:"target"`
+		AdditionalAttributes []interactionParameter `json:"additionalAttributes,omitempty"`
+		MakeCallOptions
+	}{
+		Type:                 "urn:inin.com:interactions:createCallParameters",
+		Target:               target,
+		AdditionalAttributes: asInteractionParameters(options.AdditionalAttributes),
+		MakeCallOptions:      options,
+	}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+// GetInteraction retrieves an Interaction with the given attributes
+func (session *Session) GetInteraction(interactionID string, attributes ...string) (*Interaction, error) {
+	return session.GetInteractionContext(session.Context, interactionID, attributes...)
+}
+
+// GetInteractionContext retrieves an Interaction with the given attributes
+//
+// The context cancels the request
+func (session *Session) GetInteractionContext(context context.Context, interactionID string, attributes ...string) (*Interaction, error) {
+	results := Interaction{}
+	var parameters map[string]string
+	if len(attributes) > 0 {
+		parameters = map[string]string{"select": strings.Join(attributes, ",")}
+	}
+	if _, err := session.sendContext(context, http.MethodGet, "/interactions/"+url.PathEscape(interactionID), nil, parameters, nil, &results); err != nil {
+		return nil, err
+	}
+	if len(results.ID) == 0 {
+		results.ID = interactionID
+	}
+	return &results, nil
+}
+
+// SetInteractionAttributes sets attributes of an Interaction
+func (session *Session) SetInteractionAttributes(interactionID string, attributes map[string]string) error {
+	return session.SetInteractionAttributesContext(session.Context, interactionID, attributes)
+}
+
+// SetInteractionAttributesContext sets attributes of an Interaction
+//
+// The context cancels the request
+func (session *Session) SetInteractionAttributesContext(context context.Context, interactionID string, attributes map[string]string) error {
+	return session.sendPostContext(context, "/interactions/"+url.PathEscape(interactionID), struct {
+		Attributes map[string]string `json:"attributes"`
+	}{
+		Attributes: attributes,
+	}, nil)
+}
+
+// PickupInteraction picks up an Interaction
+func (session *Session) PickupInteraction(interactionID string) error {
+	return session.PickupInteractionContext(session.Context, interactionID)
+}
+
+// PickupInteractionContext picks up an Interaction
+//
+// The context cancels the request
+func (session *Session) PickupInteractionContext(context context.Context, interactionID string) error {
+	return session.sendInteractionAction(context, interactionID, "pickup", nil)
+}
+
+// DisconnectInteraction disconnects an Interaction
+func (session *Session) DisconnectInteraction(interactionID string) error {
+	return session.DisconnectInteractionContext(session.Context, interactionID)
+}
+
+// DisconnectInteractionContext disconnects an Interaction
+//
+// The context cancels the request
+func (session *Session) DisconnectInteractionContext(context context.Context, interactionID string) error {
+	return session.sendInteractionAction(context, interactionID, "disconnect", nil)
+}
+
+// HoldInteraction puts an Interaction on or off hold
+func (session *Session) HoldInteraction(interactionID string, on bool) error {
+	return session.HoldInteractionContext(session.Context, interactionID, on)
+}
+
+// HoldInteractionContext puts an Interaction on or off hold
+//
+// The context cancels the request
+func (session *Session) HoldInteractionContext(context context.Context, interactionID string, on bool) error {
+	return session.sendInteractionAction(context, interactionID, "hold", struct {
+		On bool `json:"on"`
+	}{On: on})
+}
+
+// MuteInteraction mutes or unmutes an Interaction
+func (session *Session) MuteInteraction(interactionID string, on bool) error {
+	return session.MuteInteractionContext(session.Context, interactionID, on)
+}
+
+// MuteInteractionContext mutes or unmutes an Interaction
+//
+// The context cancels the request
+func (session *Session) MuteInteractionContext(context context.Context, interactionID string, on bool) error {
+	return session.sendInteractionAction(context, interactionID, "mute", struct {
+		On bool `json:"on"`
+	}{On: on})
+}
+
+// ParkInteraction parks an Interaction on the given target (user queue or extension)
+func (session *Session) ParkInteraction(interactionID string, target string) error {
+	return session.ParkInteractionContext(session.Context, interactionID, target)
+}
+
+// ParkInteractionContext parks an Interaction on the given target (user queue or extension)
+//
+// The context cancels the request
+func (session *Session) ParkInteractionContext(context context.Context, interactionID string, target string) error {
+	return session.sendInteractionAction(context, interactionID, "park", struct {
+		Target string `json:"target"`
+	}{Target: target})
+}
+
+// RecordInteraction starts or stops recording an Interaction
+//
+// if supervisor is true, the recording is a supervisor recording
+func (session *Session) RecordInteraction(interactionID string, on bool, supervisor bool) error {
+	return session.RecordInteractionContext(session.Context, interactionID, on, supervisor)
+}
+
+// RecordInteractionContext starts or stops recording an Interaction
+//
+// The context cancels the request
+func (session *Session) RecordInteractionContext(context context.Context, interactionID string, on bool, supervisor bool) error {
+	return session.sendInteractionAction(context, interactionID, "record", struct {
+		On         bool `json:"on"`
+		Supervisor bool `json:"supervisor"`
+	}{On: on, Supervisor: supervisor})
+}
+
+// BlindTransferInteraction transfers an Interaction to the given target without consulting it
+func (session *Session) BlindTransferInteraction(interactionID string, target string) error {
+	return session.BlindTransferInteractionContext(session.Context, interactionID, target)
+}
+
+// BlindTransferInteractionContext transfers an Interaction to the given target without consulting it
+//
+// The context cancels the request
+func (session *Session) BlindTransferInteractionContext(context context.Context, interactionID string, target string) error {
+	return session.sendInteractionAction(context, interactionID, "blind-transfer", struct {
+		Target string `json:"target"`
+	}{Target: target})
+}
+
+// ConsultTransferInteraction starts a consult transfer of an Interaction to the given target
+//
+// Use CompleteConsultTransfer or CancelConsultTransfer to finish it
+func (session *Session) ConsultTransferInteraction(interactionID string, target string) (*ConsultTransfer, error) {
+	return session.ConsultTransferInteractionContext(session.Context, interactionID, target)
+}
+
+// ConsultTransferInteractionContext starts a consult transfer of an Interaction to the given target
+//
+// The context cancels the request
+func (session *Session) ConsultTransferInteractionContext(context context.Context, interactionID string, target string) (*ConsultTransfer, error) {
+	results := ConsultTransfer{}
+	err := session.sendPostContext(context, "/interactions/"+url.PathEscape(interactionID)+"/consult-transfer", struct {
+		Target string `json:"target"`
+	}{Target: target}, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.InteractionID) == 0 {
+		results.InteractionID = interactionID
+	}
+	return &results, nil
+}
+
+// CompleteConsultTransfer completes a consult transfer
+func (session *Session) CompleteConsultTransfer(transfer ConsultTransfer) error {
+	return session.CompleteConsultTransferContext(session.Context, transfer)
+}
+
+// CompleteConsultTransferContext completes a consult transfer
+//
+// The context cancels the request
+func (session *Session) CompleteConsultTransferContext(context context.Context, transfer ConsultTransfer) error {
+	return session.sendPostContext(context, "/interactions/consult-transfers/"+url.PathEscape(transfer.ID), struct{}{}, nil)
+}
+
+// CancelConsultTransfer cancels a consult transfer, the consulted party is disconnected
+func (session *Session) CancelConsultTransfer(transfer ConsultTransfer) error {
+	return session.CancelConsultTransferContext(session.Context, transfer)
+}
+
+// CancelConsultTransferContext cancels a consult transfer, the consulted party is disconnected
+//
+// The context cancels the request
+func (session *Session) CancelConsultTransferContext(context context.Context, transfer ConsultTransfer) error {
+	return session.sendDeleteContext(context, "/interactions/consult-transfers/"+url.PathEscape(transfer.ID))
+}
+
+// ConferenceInteractions creates a conference with the given Interactions
+//
+// returns the conference Interaction
+func (session *Session) ConferenceInteractions(interactionIDs ...string) (*Interaction, error) {
+	return session.ConferenceInteractionsContext(session.Context, interactionIDs...)
+}
+
+// ConferenceInteractionsContext creates a conference with the given Interactions
+//
+// The context cancels the request
+func (session *Session) ConferenceInteractionsContext(context context.Context, interactionIDs ...string) (*Interaction, error) {
+	results := Interaction{}
+	err := session.sendPostContext(context, "/interactions/conferences", struct {
+		Interactions []string `json:"interactions"`
+	}{Interactions: interactionIDs}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+func (session *Session) sendInteractionAction(context context.Context, interactionID, action string, payload interface{}) error {
+	if payload == nil {
+		payload = struct{}{}
+	}
+	return session.sendPostContext(context, "/interactions/"+url.PathEscape(interactionID)+"/"+action, payload, nil)
+}
+
+func asInteractionParameters(attributes map[string]string) []interactionParameter {
+	parameters := make([]interactionParameter, 0, len(attributes))
+	for key, value := range attributes {
+		parameters = append(parameters, interactionParameter{Key: key, Value: value})
+	}
+	return parameters
+}
@@ -0,0 +1,114 @@
+package icws_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gildas/go-icws"
+)
+
+func (suite *SessionSuite) TestCanMakeCall() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["POST /icws/1234/interactions"] = `{"interactionId": "1001"}`
+	})
+
+	interaction, err := session.MakeCall("+13175551234", icws.MakeCallOptions{
+		Workgroup:            "Sales",
+		AdditionalAttributes: map[string]string{"Custom_Attribute": "value"},
+	})
+	suite.Require().Nil(err)
+	suite.Require().NotNil(interaction)
+	suite.Assert().Equal("1001", interaction.ID)
+
+	payload := struct {
+		Type                 string `json:"__type"`
+		Target               string `json:"target"`
+		Workgroup            string `json:"workgroup"`
+		AdditionalAttributes []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"additionalAttributes"`
+	}{}
+	suite.Require().Nil(json.Unmarshal([]byte(server.Payload(http.MethodPost, "/icws/1234/interactions")), &payload))
+	suite.Assert().Equal("urn:inin.com:interactions:createCallParameters", payload.Type)
+	suite.Assert().Equal("+13175551234", payload.Target)
+	suite.Assert().Equal("Sales", payload.Workgroup)
+	suite.Require().Len(payload.AdditionalAttributes, 1)
+	suite.Assert().Equal("Custom_Attribute", payload.AdditionalAttributes[0].Key)
+}
+
+func (suite *SessionSuite) TestCanControlInteraction() {
+	session, server := suite.NewConnectedSession()
+
+	suite.Require().Nil(session.PickupInteraction("1001"))
+	suite.Require().Nil(session.HoldInteraction("1001", true))
+	suite.Require().Nil(session.MuteInteraction("1001", false))
+	suite.Require().Nil(session.ParkInteraction("1001", "agent"))
+	suite.Require().Nil(session.RecordInteraction("1001", true, false))
+	suite.Require().Nil(session.BlindTransferInteraction("1001", "8001"))
+	suite.Require().Nil(session.DisconnectInteraction("1001"))
+
+	for _, action := range []string{"pickup", "hold", "mute", "park", "record", "blind-transfer", "disconnect"} {
+		suite.Assert().Equalf(1, server.Count(http.MethodPost, "/icws/1234/interactions/1001/"+action), "Action %s should have been sent once", action)
+	}
+	suite.Assert().JSONEq(`{"on": true}`, server.Payload(http.MethodPost, "/icws/1234/interactions/1001/hold"))
+	suite.Assert().JSONEq(`{"on": false}`, server.Payload(http.MethodPost, "/icws/1234/interactions/1001/mute"))
+	suite.Assert().JSONEq(`{"target": "8001"}`, server.Payload(http.MethodPost, "/icws/1234/interactions/1001/blind-transfer"))
+}
+
+func (suite *SessionSuite) TestCanConsultTransferInteraction() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["POST /icws/1234/interactions/1001/consult-transfer"] = `{"consultTransferId": "42", "consultInteractionId": "1002"}`
+	})
+
+	transfer, err := session.ConsultTransferInteraction("1001", "8001")
+	suite.Require().Nil(err)
+	suite.Require().NotNil(transfer)
+	suite.Assert().Equal("42", transfer.ID)
+	suite.Assert().Equal("1001", transfer.InteractionID)
+	suite.Assert().Equal("1002", transfer.ConsultInteractionID)
+	suite.Require().Nil(session.CompleteConsultTransfer(*transfer))
+	suite.Assert().Equal(1, server.Count(http.MethodPost, "/icws/1234/interactions/consult-transfers/42"))
+}
+
+const interactionAttributes = `{
+	"interactionId": "1001",
+	"attributes": {
+		"Eic_State": "H",
+		"Eic_CallDirection": "I",
+		"Eic_RemoteName": "John Doe",
+		"Eic_Muted": "1",
+		"Eic_InitiationTime": "20201231T235959Z",
+		"Custom_Count": "12"
+	}
+}`
+
+func (suite *SessionSuite) TestCanGetInteractionAttributes() {
+	selected := make(chan string, 1)
+	session, _ := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Handlers["GET /icws/1234/interactions/1001"] = func(w http.ResponseWriter, r *http.Request) {
+			selected <- r.URL.Query().Get("select")
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(interactionAttributes))
+		}
+	})
+
+	interaction, err := session.GetInteraction("1001", icws.StateAttribute, icws.RemoteNameAttribute)
+	suite.Require().Nil(err)
+	suite.Assert().Equal("Eic_State,Eic_RemoteName", <-selected)
+	suite.Require().NotNil(interaction)
+	suite.Assert().Equal(icws.HeldState, interaction.State())
+	suite.Assert().True(interaction.IsHeld())
+	suite.Assert().False(interaction.IsDisconnected())
+	suite.Assert().Equal(icws.IncomingDirection, interaction.Direction())
+	suite.Assert().Equal("John Doe", interaction.RemoteName())
+	suite.Assert().True(interaction.IsMuted())
+	suite.Assert().Equal("1001 (John Doe)", interaction.String())
+	count, ok := interaction.Attributes.GetInt("Custom_Count")
+	suite.Assert().True(ok)
+	suite.Assert().Equal(12, count)
+	initiatedAt, ok := interaction.Attributes.GetTime(icws.InitiationAttribute)
+	suite.Assert().True(ok)
+	suite.Assert().Equal(time.Date(2020, 12, 31, 23, 59, 59, 0, time.UTC), initiatedAt)
+}
@@ -0,0 +1,121 @@
+package icws
+
+import (
+	"context"
+	"time"
+
+	"github.com/gildas/go-errors"
+)
+
+// startKeepAlive starts checking periodically that the PureConnect session is still alive
+//
+// Nothing is done if the keepalive is disabled or already running
+func (session *Session) startKeepAlive() {
+	if session.KeepAliveInterval <= 0 {
+		return
+	}
+	parent := session.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	session.mutex.Lock()
+	if session.keepAliveCancel != nil {
+		session.mutex.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(parent)
+	done := make(chan struct{})
+	session.keepAliveCancel = cancel
+	session.keepAliveDone = done
+	session.mutex.Unlock()
+
+	go func() {
+		defer close(done)
+		log := session.Logger.Child(nil, "keepalive")
+		ticker := time.NewTicker(session.KeepAliveInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			sessionID := session.GetID()
+			err := session.sendGet("/connection", nil)
+			if err == nil {
+				log.Tracef("Session %s is alive", sessionID)
+				continue
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if !errors.Is(err, errors.HTTPUnauthorized) && !errors.Is(err, errors.HTTPNotFound) {
+				log.Warnf("Failed to check the session: %s", err.Error())
+				continue
+			}
+			if !session.expire(ctx, sessionID) {
+				session.mutex.Lock()
+				session.keepAliveCancel, session.keepAliveDone = nil, nil
+				session.mutex.Unlock()
+				cancel()
+				return
+			}
+		}
+	}()
+}
+
+// stopKeepAlive stops the keepalive, if running
+func (session *Session) stopKeepAlive() {
+	session.mutex.Lock()
+	cancel, done := session.keepAliveCancel, session.keepAliveDone
+	session.keepAliveCancel, session.keepAliveDone = nil, nil
+	session.mutex.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// expire processes the expiration of the PureConnect session sessionID
+//
+// returns true if the Session reconnected
+func (session *Session) expire(ctx context.Context, sessionID string) bool {
+	log := session.Logger.Child(nil, "expire")
+
+	log.Warnf("Session %s expired", sessionID)
+	session.eventStream.stop()
+	session.mutex.Lock()
+	if session.ID == sessionID {
+		session.Status = DisconnectedStatus
+	}
+	session.mutex.Unlock()
+	session.eventStream.notify(ctx, SessionExpiredMessage{
+		SessionID:    sessionID,
+		ExpiredAt:    time.Now(),
+		Reconnecting: session.KeepAliveReconnect,
+	})
+	if !session.KeepAliveReconnect {
+		return false
+	}
+	for {
+		_, err := session.recover(sessionID, nil)
+		if err == nil {
+			break
+		}
+		log.Errorf("Failed to reconnect, retrying in %s", session.KeepAliveInterval, err)
+		select {
+		case <-time.After(session.KeepAliveInterval):
+		case <-ctx.Done():
+			return false
+		}
+		sessionID = session.GetID()
+	}
+	log.Infof("Session reconnected as %s", session.GetID())
+	session.eventStream.notify(ctx, SessionReconnectedMessage{
+		SessionID:     session.GetID(),
+		ReconnectedAt: time.Now(),
+	})
+	return true
+}
@@ -1,5 +1,22 @@
 package icws
 
+import (
+	"bytes"
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gildas/go-errors"
+)
+
+// MissingLicense is used when the User does not have the licenses needed for an operation
+var MissingLicense = errors.NewSentinel(http.StatusForbidden, "error.icws.license.missing", "Missing license")
+
+// LicenseNotAcquired is used when a License could not be acquired or released
+var LicenseNotAcquired = errors.NewSentinel(http.StatusConflict, "error.icws.license.notacquired", "License not acquired")
+
 // License describes a PureConnect License
 type License struct {
 	Name       string `json:"name"`
@@ -21,6 +38,7 @@ type AdditionalLicense struct {
 	SelfURI string `json:"uri"`
 }
 
+// AllocationLicense tells how the licenses of a User are allocated
 type AllocationLicense uint32
 
 const (
@@ -28,6 +46,12 @@ const (
 	ConcurrentAllocation
 )
 
+// UnknownAllocation is used when decoding an AllocationLicense name PureConnect sent that this package does not know
+//
+// Unknown numbers are kept as they are, so they can be sent back to PureConnect.
+const UnknownAllocation AllocationLicense = math.MaxUint32
+
+// MediaLicense tells how many media types a User can handle at once
 type MediaLicense uint32
 
 const (
@@ -36,6 +60,12 @@ const (
 	Media3Plus
 )
 
+// UnknownMediaLicense is used when decoding a MediaLicense name PureConnect sent that this package does not know
+//
+// Unknown numbers are kept as they are, so they can be sent back to PureConnect.
+const UnknownMediaLicense MediaLicense = math.MaxUint32
+
+// IPALicense is the Interaction Process Automation license of a User
 type IPALicense uint32
 
 const (
@@ -44,3 +74,383 @@ const (
 	IPAProcessMonitor
 	IPAProcessDesigner
 )
+
+// UnknownIPALicense is used when decoding an IPALicense name PureConnect sent that this package does not know
+//
+// Unknown numbers are kept as they are, so they can be sent back to PureConnect.
+const UnknownIPALicense IPALicense = math.MaxUint32
+
+// licenseWireValue tells how a license enum value is sent by PureConnect, as a number or as a name
+type licenseWireValue[T ~uint32] struct {
+	value  T
+	number uint64
+	name   string
+}
+
+// allocationLicenseWireValues is the only place where AllocationLicense values are mapped to PureConnect
+var allocationLicenseWireValues = []licenseWireValue[AllocationLicense]{
+	{AssignableAllocation, 0, "Assignable"},
+	{ConcurrentAllocation, 1, "Concurrent"},
+}
+
+// mediaLicenseWireValues is the only place where MediaLicense values are mapped to PureConnect
+var mediaLicenseWireValues = []licenseWireValue[MediaLicense]{
+	{Media1, 0, "Media1"},
+	{Media2, 1, "Media2"},
+	{Media3Plus, 2, "Media3Plus"},
+}
+
+// ipaLicenseWireValues is the only place where IPALicense values are mapped to PureConnect
+var ipaLicenseWireValues = []licenseWireValue[IPALicense]{
+	{IPADirectRoutedWorkItems, 0, "DirectRoutedWorkItems"},
+	{IPAGroupRoutedWorkItems, 1, "GroupRoutedWorkItems"},
+	{IPAProcessMonitor, 2, "ProcessMonitor"},
+	{IPAProcessDesigner, 3, "ProcessDesigner"},
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (license AllocationLicense) String() string {
+	return licenseEnumString(license, allocationLicenseWireValues)
+}
+
+// MarshalText marshals into text
+//
+// Known values are marshaled as their name, unknown values as the number PureConnect sent.
+//
+// implements encoding.TextMarshaler
+func (license AllocationLicense) MarshalText() ([]byte, error) {
+	return licenseEnumText(license, allocationLicenseWireValues, UnknownAllocation), nil
+}
+
+// UnmarshalText unmarshals from text
+//
+// Names are case insensitive, numbers are accepted too.
+//
+// implements encoding.TextUnmarshaler
+func (license *AllocationLicense) UnmarshalText(text []byte) error {
+	*license = parseLicenseEnum(string(text), allocationLicenseWireValues, UnknownAllocation)
+	return nil
+}
+
+// MarshalJSON marshals into JSON
+//
+// The license is marshaled as the number PureConnect uses.
+//
+// implements json.Marshaler
+func (license AllocationLicense) MarshalJSON() ([]byte, error) {
+	return licenseEnumJSON(license, allocationLicenseWireValues, UnknownAllocation), nil
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// PureConnect sends a number or a name (case insensitive).
+// Unknown numbers are kept, unknown names become UnknownAllocation.
+//
+// implements json.Unmarshaler
+func (license *AllocationLicense) UnmarshalJSON(payload []byte) error {
+	if string(payload) == "null" {
+		return nil
+	}
+	return license.UnmarshalText(bytes.Trim(payload, `"`))
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (license MediaLicense) String() string {
+	return licenseEnumString(license, mediaLicenseWireValues)
+}
+
+// MarshalText marshals into text
+//
+// Known values are marshaled as their name, unknown values as the number PureConnect sent.
+//
+// implements encoding.TextMarshaler
+func (license MediaLicense) MarshalText() ([]byte, error) {
+	return licenseEnumText(license, mediaLicenseWireValues, UnknownMediaLicense), nil
+}
+
+// UnmarshalText unmarshals from text
+//
+// Names are case insensitive, numbers are accepted too.
+//
+// implements encoding.TextUnmarshaler
+func (license *MediaLicense) UnmarshalText(text []byte) error {
+	*license = parseLicenseEnum(string(text), mediaLicenseWireValues, UnknownMediaLicense)
+	return nil
+}
+
+// MarshalJSON marshals into JSON
+//
+// The license is marshaled as the number PureConnect uses.
+//
+// implements json.Marshaler
+func (license MediaLicense) MarshalJSON() ([]byte, error) {
+	return licenseEnumJSON(license, mediaLicenseWireValues, UnknownMediaLicense), nil
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// PureConnect sends a number or a name (case insensitive).
+// Unknown numbers are kept, unknown names become UnknownMediaLicense.
+//
+// implements json.Unmarshaler
+func (license *MediaLicense) UnmarshalJSON(payload []byte) error {
+	if string(payload) == "null" {
+		return nil
+	}
+	return license.UnmarshalText(bytes.Trim(payload, `"`))
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (license IPALicense) String() string {
+	return licenseEnumString(license, ipaLicenseWireValues)
+}
+
+// MarshalText marshals into text
+//
+// Known values are marshaled as their name, unknown values as the number PureConnect sent.
+//
+// implements encoding.TextMarshaler
+func (license IPALicense) MarshalText() ([]byte, error) {
+	return licenseEnumText(license, ipaLicenseWireValues, UnknownIPALicense), nil
+}
+
+// UnmarshalText unmarshals from text
+//
+// Names are case insensitive, numbers are accepted too.
+//
+// implements encoding.TextUnmarshaler
+func (license *IPALicense) UnmarshalText(text []byte) error {
+	*license = parseLicenseEnum(string(text), ipaLicenseWireValues, UnknownIPALicense)
+	return nil
+}
+
+// MarshalJSON marshals into JSON
+//
+// The license is marshaled as the number PureConnect uses.
+//
+// implements json.Marshaler
+func (license IPALicense) MarshalJSON() ([]byte, error) {
+	return licenseEnumJSON(license, ipaLicenseWireValues, UnknownIPALicense), nil
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// PureConnect sends a number or a name (case insensitive).
+// Unknown numbers are kept, unknown names become UnknownIPALicense.
+//
+// implements json.Unmarshaler
+func (license *IPALicense) UnmarshalJSON(payload []byte) error {
+	if string(payload) == "null" {
+		return nil
+	}
+	return license.UnmarshalText(bytes.Trim(payload, `"`))
+}
+
+func licenseEnumString[T ~uint32](value T, wireValues []licenseWireValue[T]) string {
+	for _, wireValue := range wireValues {
+		if wireValue.value == value {
+			return wireValue.name
+		}
+	}
+	return "Unknown"
+}
+
+func licenseEnumText[T ~uint32](value T, wireValues []licenseWireValue[T], unknown T) []byte {
+	for _, wireValue := range wireValues {
+		if wireValue.value == value {
+			return []byte(wireValue.name)
+		}
+	}
+	if value == unknown {
+		return []byte("Unknown")
+	}
+	return []byte(strconv.FormatUint(uint64(value), 10))
+}
+
+func licenseEnumJSON[T ~uint32](value T, wireValues []licenseWireValue[T], unknown T) []byte {
+	for _, wireValue := range wireValues {
+		if wireValue.value == value {
+			return []byte(strconv.FormatUint(wireValue.number, 10))
+		}
+	}
+	if value == unknown {
+		return []byte(`"Unknown"`) // There is no number to send back, only PureConnect knows what the name was
+	}
+	return []byte(strconv.FormatUint(uint64(value), 10))
+}
+
+func parseLicenseEnum[T ~uint32](text string, wireValues []licenseWireValue[T], unknown T) T {
+	text = strings.TrimSpace(text)
+	if number, err := strconv.ParseUint(text, 10, 32); err == nil {
+		for _, wireValue := range wireValues {
+			if wireValue.number == number {
+				return wireValue.value
+			}
+		}
+		return T(number) // Keep the number so the value can be sent back as is
+	}
+	normalized := strings.ReplaceAll(text, " ", "")
+	for _, wireValue := range wireValues {
+		if strings.EqualFold(normalized, wireValue.name) {
+			return wireValue.value
+		}
+	}
+	return unknown
+}
+
+// LicenseOperationResult is the result of acquiring or releasing a License
+type LicenseOperationResult struct {
+	License    string        `json:"licenseName"`
+	IsAssigned bool          `json:"isAssigned"`
+	Details    *LicenseError `json:"errorDetails,omitempty"`
+}
+
+// LicenseError describes why a License operation failed
+type LicenseError struct {
+	ID      string `json:"errorId"`
+	Message string `json:"message"`
+}
+
+// AcquireLicenses acquires Licenses for the User of the Session
+//
+// The results tell for each License if it is assigned and why it is not.
+// If some Licenses could not be acquired, the error contains a LicenseNotAcquired per License.
+func (session *Session) AcquireLicenses(licenses []string) ([]LicenseOperationResult, error) {
+	return session.AcquireLicensesContext(session.Context, licenses)
+}
+
+// AcquireLicensesContext acquires Licenses for the User of the Session
+//
+// The context cancels the request
+func (session *Session) AcquireLicensesContext(context context.Context, licenses []string) ([]LicenseOperationResult, error) {
+	if len(licenses) == 0 {
+		return nil, errors.ArgumentMissing.With("licenses")
+	}
+	results := struct {
+		Results []LicenseOperationResult `json:"licenseOperationResultList"`
+	}{}
+	err := session.sendPostContext(context, "/licenses", LicenseSubscription{Licenses: licenses}, &results)
+	if err != nil {
+		return nil, err
+	}
+	var errs errors.MultiError
+	for _, result := range results.Results {
+		if !result.IsAssigned {
+			errs.Append(result.Err())
+		}
+	}
+	return results.Results, errs.AsError()
+}
+
+// ReleaseLicenses releases Licenses of the User of the Session
+//
+// The results tell for each License if it is still assigned and why.
+// If some Licenses could not be released, the error contains a LicenseNotAcquired per License.
+func (session *Session) ReleaseLicenses(licenses []string) ([]LicenseOperationResult, error) {
+	return session.ReleaseLicensesContext(session.Context, licenses)
+}
+
+// ReleaseLicensesContext releases Licenses of the User of the Session
+//
+// The context cancels the request
+func (session *Session) ReleaseLicensesContext(context context.Context, licenses []string) ([]LicenseOperationResult, error) {
+	if len(licenses) == 0 {
+		return nil, errors.ArgumentMissing.With("licenses")
+	}
+	results := struct {
+		Results []LicenseOperationResult `json:"licenseOperationResultList"`
+	}{}
+	_, err := session.sendContext(context, http.MethodDelete, "/licenses", nil, map[string]string{"licenseList": strings.Join(licenses, ",")}, nil, &results)
+	if err != nil {
+		return nil, err
+	}
+	var errs errors.MultiError
+	for _, result := range results.Results {
+		if result.Details != nil {
+			errs.Append(result.Err())
+		}
+	}
+	return results.Results, errs.AsError()
+}
+
+// Err gives the error of the operation, if any
+func (result LicenseOperationResult) Err() error {
+	if result.Details == nil && result.IsAssigned {
+		return nil
+	}
+	err := LicenseNotAcquired.With("license", result.License)
+	if result.Details != nil && len(result.Details.Message) > 0 {
+		return errors.WithMessage(err, result.Details.Message)
+	}
+	return err
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (result LicenseOperationResult) String() string {
+	if result.IsAssigned {
+		return result.License + ": assigned"
+	}
+	if result.Details != nil && len(result.Details.Message) > 0 {
+		return result.License + ": not assigned (" + result.Details.Message + ")"
+	}
+	return result.License + ": not assigned"
+}
+
+// CheckStationLicenses verifies the User of the Session has the licenses to connect to a Station
+//
+// The User needs an active client access license and a media license covering the media types of the Station.
+// If the User configuration cannot be read (e.g. missing rights), the check is skipped.
+func (session *Session) CheckStationLicenses(settings StationSettings) error {
+	return session.CheckStationLicensesContext(session.Context, settings)
+}
+
+// CheckStationLicensesContext verifies the User of the Session has the licenses to connect to a Station
+//
+// The context cancels the request
+func (session *Session) CheckStationLicensesContext(context context.Context, settings StationSettings) error {
+	session.mutex.RLock()
+	userID := session.User.ID
+	session.mutex.RUnlock()
+
+	user, err := GetConfigurationContext[UserConfiguration](context, session, userID, QueryOptions{Fields: []string{"licenseProperties"}})
+	if errors.Is(err, errors.HTTPForbidden) || errors.Is(err, errors.HTTPNotFound) {
+		session.Logger.Child(nil, "license").Warnf("Cannot read the licenses of user %s, skipping the check: %s", userID, err)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	license := user.LicenseProperties
+	if !license.Active || !license.HasClientAccess {
+		return MissingLicense.With("license", "client access")
+	}
+	mediaTypes := map[MediaType]bool{}
+	for _, mediaType := range settings.MediaTypes() {
+		if mediaType != NoMedia {
+			mediaTypes[mediaType] = true
+		}
+	}
+	if len(mediaTypes) > license.MediaLicense.MediaCount() {
+		return MissingLicense.With("license", "media")
+	}
+	return nil
+}
+
+// MediaCount tells how many media types the MediaLicense allows at once
+func (license MediaLicense) MediaCount() int {
+	switch license {
+	case Media1:
+		return 1
+	case Media2:
+		return 2
+	default:
+		return int(^uint(0) >> 1)
+	}
+}
@@ -1,6 +1,7 @@
 package icws
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/gildas/go-errors"
@@ -30,15 +31,15 @@ func (message LicenseMessage) GetType() string {
 // Subscribe subscribe a Session to this type of messages
 //
 // implements Subscriber
-func (message LicenseMessage) Subscribe(session *Session, payload interface{}) error {
-	return session.sendPut("/messaging/subscriptions/licenses", payload, nil)
+func (message LicenseMessage) Subscribe(context context.Context, session *Session, payload interface{}) error {
+	return session.sendPutContext(context, "/messaging/subscriptions/licenses", payload, nil)
 }
 
 // Subscribe subscribe a Session to this type of messages
 //
 // implements Unsubscriber
-func (message LicenseMessage) Unsubscribe(session *Session) error {
-	return session.sendDelete("/messaging/subscriptions/licenses")
+func (message LicenseMessage) Unsubscribe(context context.Context, session *Session) error {
+	return session.sendDeleteContext(context, "/messaging/subscriptions/licenses")
 }
 
 // MarshalJSON marshals into JSON
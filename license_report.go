@@ -0,0 +1,151 @@
+package icws
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LicenseReport summarizes how licenses are allocated to the Users of the organization
+//
+// In JSON, the counts are keyed by license name (e.g. "Media3Plus") while the rows keep the numbers PureConnect uses.
+type LicenseReport struct {
+	Users        int                       `json:"users"`
+	Active       int                       `json:"active"`
+	ClientAccess int                       `json:"clientAccess"`
+	Media        map[MediaLicense]int      `json:"media"`
+	Allocation   map[AllocationLicense]int `json:"allocation"`
+	IPA          map[IPALicense]int        `json:"ipa"`
+	Additional   map[string]int            `json:"additional"`
+	Rows         []LicenseReportRow        `json:"rows"`
+}
+
+// LicenseReportRow describes the licenses of a User in a LicenseReport
+type LicenseReportRow struct {
+	UserID       string            `json:"userId"`
+	DisplayName  string            `json:"displayName,omitempty"`
+	Active       bool              `json:"active"`
+	ClientAccess bool              `json:"clientAccess"`
+	Media        MediaLicense      `json:"media"`
+	Allocation   AllocationLicense `json:"allocation"`
+	IPA          IPALicense        `json:"ipa"`
+	Additional   []string          `json:"additional,omitempty"`
+}
+
+// NewLicenseReport builds a LicenseReport from the given Users
+//
+// The Users must have been retrieved with their licenseProperties.
+func NewLicenseReport(users []User) *LicenseReport {
+	report := &LicenseReport{
+		Media:      map[MediaLicense]int{},
+		Allocation: map[AllocationLicense]int{},
+		IPA:        map[IPALicense]int{},
+		Additional: map[string]int{},
+		Rows:       []LicenseReportRow{},
+	}
+	for _, user := range users {
+		report.Add(user)
+	}
+	return report
+}
+
+// GetLicenseReport builds a LicenseReport over all the Users of the organization
+//
+// The Users matching options.Where are walked one page at a time through ForEachUser, their licenseProperties are always selected.
+func (session *Session) GetLicenseReport(options QueryOptions) (*LicenseReport, error) {
+	return session.GetLicenseReportContext(session.Context, options)
+}
+
+// GetLicenseReportContext builds a LicenseReport over all the Users of the organization
+//
+// The context cancels the requests
+func (session *Session) GetLicenseReportContext(context context.Context, options QueryOptions) (*LicenseReport, error) {
+	selected := false
+	for _, field := range options.Fields {
+		selected = selected || field == "licenseProperties"
+	}
+	if !selected {
+		options.Fields = append(append(QueryFieldSelector{}, options.Fields...), "licenseProperties")
+	}
+	report := NewLicenseReport(nil)
+	err := session.ForEachUser(PageOptions{Context: context, Query: options}, func(user User) error {
+		report.Add(user)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Add adds the licenses of the given User to the LicenseReport
+func (report *LicenseReport) Add(user User) {
+	license := user.License
+	row := LicenseReportRow{
+		UserID:       user.ID,
+		DisplayName:  user.DisplayName,
+		Active:       license.Active,
+		ClientAccess: license.HasClientAccess,
+		Media:        license.MediaLicense,
+		Allocation:   license.AllocationType,
+		IPA:          license.IPALicense,
+	}
+	report.Users++
+	if license.Active {
+		report.Active++
+		report.Media[license.MediaLicense]++
+		report.Allocation[license.AllocationType]++
+		report.IPA[license.IPALicense]++
+	}
+	if license.HasClientAccess {
+		report.ClientAccess++
+	}
+	for _, additional := range license.AdditionalLicenses {
+		name := additional.Name
+		if len(name) == 0 {
+			name = additional.ID
+		}
+		row.Additional = append(row.Additional, name)
+		report.Additional[name]++
+	}
+	report.Rows = append(report.Rows, row)
+}
+
+// WriteCSV writes a line per User of the LicenseReport, sorted by User ID, as CSV
+func (report LicenseReport) WriteCSV(writer io.Writer) error {
+	rows := make([]LicenseReportRow, len(report.Rows))
+	copy(rows, report.Rows)
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].UserID < rows[j].UserID })
+
+	output := csv.NewWriter(writer)
+	if err := output.Write([]string{"User", "Name", "Active", "Client Access", "Media", "Allocation", "IPA", "Additional"}); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := []string{
+			row.UserID,
+			row.DisplayName,
+			strconv.FormatBool(row.Active),
+			strconv.FormatBool(row.ClientAccess),
+			row.Media.String(),
+			row.Allocation.String(),
+			row.IPA.String(),
+			strings.Join(row.Additional, ";"),
+		}
+		if err := output.Write(record); err != nil {
+			return err
+		}
+	}
+	output.Flush()
+	return output.Error()
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (report LicenseReport) String() string {
+	return strconv.Itoa(report.Active) + "/" + strconv.Itoa(report.Users) + " active licenses"
+}
@@ -0,0 +1,59 @@
+package icws_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/gildas/go-icws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *SessionSuite) TestCanBuildLicenseReport() {
+	session, _ := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["GET /icws/1234/configuration/users"] = `{"items": [
+			{"configurationId": {"id": "bob", "displayName": "Bob"}, "licenseProperties": {"licenseActive": true, "hasClientAccess": true, "mediaLevel": 2, "allocationType": 0, "additionalLicenses": [{"id": "I3_ACCESS_DIALER_ADDON"}]}},
+			{"configurationId": {"id": "alice", "displayName": "Alice"}, "licenseProperties": {"licenseActive": true, "hasClientAccess": true, "mediaLevel": "Media1", "allocationType": "Concurrent"}},
+			{"configurationId": {"id": "carol", "displayName": "Carol"}, "licenseProperties": {"licenseActive": false}}
+		]}`
+	})
+
+	report, err := session.GetLicenseReport(icws.QueryOptions{})
+	suite.Require().Nil(err)
+	suite.Assert().Equal(3, report.Users)
+	suite.Assert().Equal(2, report.Active)
+	suite.Assert().Equal(2, report.ClientAccess)
+	suite.Assert().Equal(1, report.Media[icws.Media1])
+	suite.Assert().Equal(1, report.Media[icws.Media3Plus])
+	suite.Assert().Equal(1, report.Allocation[icws.ConcurrentAllocation])
+	suite.Assert().Equal(1, report.Additional["I3_ACCESS_DIALER_ADDON"])
+	suite.Assert().Equal("2/3 active licenses", report.String())
+
+	var output bytes.Buffer
+	suite.Require().Nil(report.WriteCSV(&output))
+	suite.Assert().Equal(`User,Name,Active,Client Access,Media,Allocation,IPA,Additional
+alice,Alice,true,true,Media1,Concurrent,DirectRoutedWorkItems,
+bob,Bob,true,true,Media3Plus,Assignable,DirectRoutedWorkItems,I3_ACCESS_DIALER_ADDON
+carol,Carol,false,false,Media1,Assignable,DirectRoutedWorkItems,
+`, output.String())
+}
+
+func TestCanMarshalLicenseReport(t *testing.T) {
+	report := icws.NewLicenseReport([]icws.User{})
+	report.Media[icws.Media3Plus] = 2
+	report.Allocation[icws.ConcurrentAllocation] = 1
+	report.IPA[icws.IPALicense(9)] = 1
+
+	payload, err := json.Marshal(report)
+	require.Nil(t, err)
+	assert.Contains(t, string(payload), `"media":{"Media3Plus":2}`)
+	assert.Contains(t, string(payload), `"allocation":{"Concurrent":1}`)
+	assert.Contains(t, string(payload), `"ipa":{"9":1}`)
+
+	var decoded icws.LicenseReport
+	require.Nil(t, json.Unmarshal(payload, &decoded))
+	assert.Equal(t, report.Media, decoded.Media)
+	assert.Equal(t, report.Allocation, decoded.Allocation)
+	assert.Equal(t, report.IPA, decoded.IPA)
+}
@@ -0,0 +1,103 @@
+package icws_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-icws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *SessionSuite) TestCanAcquireLicenses() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["POST /icws/1234/licenses"] = `{"licenseOperationResultList": [
+			{"licenseName": "I3_ACCESS_CLIENT", "isAssigned": true},
+			{"licenseName": "I3_ACCESS_DIALER_ADDON", "isAssigned": false, "errorDetails": {"errorId": "error.license.unavailable", "message": "No license available"}}
+		]}`
+	})
+
+	results, err := session.AcquireLicenses([]string{"I3_ACCESS_CLIENT", "I3_ACCESS_DIALER_ADDON"})
+	suite.Require().NotNil(err)
+	suite.Assert().Truef(errors.Is(err, icws.LicenseNotAcquired), "Error should be a LicenseNotAcquired, got %s", err)
+	suite.Require().Len(results, 2)
+	suite.Assert().Nil(results[0].Err())
+	suite.Assert().Equal("I3_ACCESS_DIALER_ADDON: not assigned (No license available)", results[1].String())
+	suite.Assert().JSONEq(`{"licenseList": ["I3_ACCESS_CLIENT", "I3_ACCESS_DIALER_ADDON"]}`, server.Payload(http.MethodPost, "/icws/1234/licenses"))
+
+	_, err = session.ReleaseLicenses([]string{"I3_ACCESS_CLIENT"})
+	suite.Require().Nil(err)
+	suite.Assert().Equal(1, server.Count(http.MethodDelete, "/icws/1234/licenses"))
+}
+
+func (suite *SessionSuite) TestCanTrackLicenses() {
+	session, server := suite.NewConnectedSession()
+
+	tracker, err := icws.NewLicenseTracker(session, "I3_ACCESS_CLIENT", "I3_ACCESS_DIALER_ADDON")
+	suite.Require().Nil(err)
+	suite.Assert().JSONEq(`{"licenseList": ["I3_ACCESS_CLIENT", "I3_ACCESS_DIALER_ADDON"]}`, server.Payload(http.MethodPut, "/icws/1234/messaging/subscriptions/licenses"))
+
+	server.StreamEvents <- "data: {\"__type\": \"urn:inin.com:status:licenseMessage\", \"isDelta\": false, \"licenseAssignedStatusList\": [{\"name\": \"I3_ACCESS_CLIENT\", \"isAssigned\": true}]}\n\n"
+	suite.Require().Eventually(func() bool { return tracker.IsAssigned("I3_ACCESS_CLIENT") }, 5*time.Second, 10*time.Millisecond)
+	suite.Assert().False(tracker.IsAssigned("I3_ACCESS_DIALER_ADDON"))
+	suite.Assert().Equal([]string{"I3_ACCESS_CLIENT"}, tracker.Assigned())
+
+	suite.Require().Nil(tracker.Close())
+	suite.Assert().Equal(1, server.Count(http.MethodDelete, "/icws/1234/messaging/subscriptions/licenses"))
+}
+
+func TestCanUnmarshalLicenseProperties(t *testing.T) {
+	payloads := []string{
+		`{"licenseActive": true, "mediaLevel": 1, "allocationType": 1, "interactionProcessorAutomationType": 3}`,
+		`{"licenseActive": true, "mediaLevel": "Media2", "allocationType": "concurrent", "interactionProcessorAutomationType": "Process Designer"}`,
+	}
+	for _, payload := range payloads {
+		var properties icws.LicenseProperties
+		require.Nil(t, json.Unmarshal([]byte(payload), &properties), payload)
+		assert.Equal(t, icws.Media2, properties.MediaLicense, payload)
+		assert.Equal(t, icws.ConcurrentAllocation, properties.AllocationType, payload)
+		assert.Equal(t, icws.IPAProcessDesigner, properties.IPALicense, payload)
+	}
+}
+
+func TestShouldTolerateUnknownLicenses(t *testing.T) {
+	var properties icws.LicenseProperties
+	payload := `{"mediaLevel": "MediaUnlimited", "allocationType": 7, "interactionProcessorAutomationType": null}`
+	require.Nil(t, json.Unmarshal([]byte(payload), &properties))
+	assert.Equal(t, icws.UnknownMediaLicense, properties.MediaLicense)
+	assert.Equal(t, "Unknown", properties.MediaLicense.String())
+	assert.Equal(t, icws.AllocationLicense(7), properties.AllocationType, "Unknown numbers should be kept")
+	assert.Equal(t, "Unknown", properties.AllocationType.String())
+	assert.Equal(t, icws.IPADirectRoutedWorkItems, properties.IPALicense)
+
+	marshaled, err := json.Marshal(properties)
+	require.Nil(t, err)
+	assert.Contains(t, string(marshaled), `"allocationType":7`)
+	assert.Contains(t, string(marshaled), `"mediaLevel":"Unknown"`)
+	text, err := properties.AllocationType.MarshalText()
+	require.Nil(t, err)
+	assert.Equal(t, "7", string(text))
+}
+
+func TestCanRoundTripLicenses(t *testing.T) {
+	properties := icws.LicenseProperties{
+		Active:         true,
+		MediaLicense:   icws.Media3Plus,
+		AllocationType: icws.AssignableAllocation,
+		IPALicense:     icws.IPAGroupRoutedWorkItems,
+	}
+	payload, err := json.Marshal(properties)
+	require.Nil(t, err)
+	assert.Contains(t, string(payload), `"mediaLevel":2`)
+	assert.Contains(t, string(payload), `"allocationType":0`)
+	assert.Contains(t, string(payload), `"interactionProcessorAutomationType":1`)
+
+	var decoded icws.LicenseProperties
+	require.Nil(t, json.Unmarshal(payload, &decoded))
+	assert.Equal(t, properties.MediaLicense, decoded.MediaLicense)
+	assert.Equal(t, properties.AllocationType, decoded.AllocationType)
+	assert.Equal(t, properties.IPALicense, decoded.IPALicense)
+}
@@ -0,0 +1,94 @@
+package icws
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// LicenseTracker tracks which Licenses are assigned to the User of a Session
+//
+// The tracker subscribes the Session to the License changes and applies them
+// in the order they are received.
+type LicenseTracker struct {
+	messageFeed[LicenseMessage]
+	licenses map[string]bool
+	mutex    sync.RWMutex
+}
+
+// NewLicenseTracker creates a new LicenseTracker for the given Licenses
+//
+// If session is not nil, the Session is subscribed to the changes of these Licenses and they are applied to the tracker.
+func NewLicenseTracker(session *Session, licenses ...string) (*LicenseTracker, error) {
+	if session == nil {
+		return NewLicenseTrackerContext(context.Background(), nil, licenses...)
+	}
+	return NewLicenseTrackerContext(session.Context, session, licenses...)
+}
+
+// NewLicenseTrackerContext creates a new LicenseTracker for the given Licenses
+//
+// The context cancels the subscription request
+func NewLicenseTrackerContext(context context.Context, session *Session, licenses ...string) (*LicenseTracker, error) {
+	tracker := &LicenseTracker{licenses: map[string]bool{}}
+	for _, license := range licenses {
+		tracker.licenses[license] = false
+	}
+	if session == nil {
+		return tracker, nil
+	}
+	tracker.start(session, tracker.Apply)
+	if err := session.SubscribeContext(context, LicenseMessage{}, LicenseSubscription{Licenses: licenses}); err != nil {
+		tracker.stop()
+		return nil, err
+	}
+	return tracker, nil
+}
+
+// Apply applies the changes of a LicenseMessage
+func (tracker *LicenseTracker) Apply(message LicenseMessage) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	if !message.IsDelta {
+		for license := range tracker.licenses {
+			tracker.licenses[license] = false
+		}
+	}
+	for _, license := range message.Licenses {
+		tracker.licenses[license.Name] = license.IsAssigned
+	}
+}
+
+// IsAssigned tells if the License is currently assigned
+func (tracker *LicenseTracker) IsAssigned(license string) bool {
+	tracker.mutex.RLock()
+	defer tracker.mutex.RUnlock()
+	return tracker.licenses[license]
+}
+
+// Assigned gives the Licenses that are currently assigned, sorted
+func (tracker *LicenseTracker) Assigned() []string {
+	tracker.mutex.RLock()
+	defer tracker.mutex.RUnlock()
+	licenses := []string{}
+	for license, assigned := range tracker.licenses {
+		if assigned {
+			licenses = append(licenses, license)
+		}
+	}
+	sort.Strings(licenses)
+	return licenses
+}
+
+// Close unsubscribes the Session from the License changes and stops feeding the tracker
+func (tracker *LicenseTracker) Close() error {
+	if tracker.session == nil {
+		return nil
+	}
+	var err error
+	if tracker.session.IsConnected() {
+		err = tracker.session.Unsubscribe(LicenseMessage{})
+	}
+	tracker.stop()
+	return err
+}
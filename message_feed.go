@@ -0,0 +1,43 @@
+package icws
+
+// messageFeed feeds a consumer with the Messages of type T received by a Session
+//
+// The zero value is not fed by any Session, stop does nothing then.
+type messageFeed[T Message] struct {
+	session *Session
+	events  chan EventSource
+	done    chan struct{}
+}
+
+// start feeds apply with the Messages of type T received by the Session
+//
+// The Events chan blocks on overflow so no Message is lost. apply is called for one Message at a time,
+// in the order they are received, until stop is called or the Session is disconnected.
+func (feed *messageFeed[T]) start(session *Session, apply func(T)) {
+	feed.session = session
+	feed.events = session.EventsWithOptions(EventsOptions{
+		Overflow: BlockOnOverflow,
+		filter: func(message Message) bool {
+			_, ok := asMessage[T](message)
+			return ok
+		},
+	})
+	feed.done = make(chan struct{})
+	go func() {
+		defer close(feed.done)
+		for event := range feed.events {
+			if message, ok := asMessage[T](event.Message); ok {
+				apply(message)
+			}
+		}
+	}()
+}
+
+// stop stops feeding, once the Message being applied (if any) is done
+func (feed *messageFeed[T]) stop() {
+	if feed.session == nil {
+		return
+	}
+	feed.session.ReleaseEvents(feed.events)
+	<-feed.done
+}
@@ -1,6 +1,7 @@
 package icws_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -92,6 +93,66 @@ func (suite *MessageSuite) TestCanUnmarshalUserStatusMessage() {
 	suite.Assert().Len(actual.UserStatuses, 4)
 }
 
+func (suite *MessageSuite) TestCanUnmarshalQueueMessage() {
+	payload := suite.LoadTestData("queuemessage.json")
+	message, err := icws.UnmarshalMessage(payload)
+	suite.Require().Nil(err)
+	suite.Require().NotNil(message)
+
+	actual, ok := message.(*icws.QueueMessage)
+	suite.Require().Truef(ok, "Wrong Type: %s", reflect.TypeOf(message).Name())
+	suite.Assert().Equal("mine", actual.SubscriptionID)
+	suite.Assert().True(actual.IsDelta)
+	suite.Require().Len(actual.InteractionsAdded, 1)
+	suite.Assert().Equal("John Doe", actual.InteractionsAdded[0].RemoteName())
+	suite.Require().Len(actual.InteractionsChanged, 1)
+	suite.Assert().Equal(icws.HeldState, actual.InteractionsChanged[0].State())
+	suite.Assert().Equal([]string{"999"}, actual.InteractionsRemoved)
+}
+
+func (suite *MessageSuite) TestCanUnmarshalStatisticValueMessage() {
+	payload := suite.LoadTestData("statisticvaluemessage.json")
+	message, err := icws.UnmarshalMessage(payload)
+	suite.Require().Nil(err)
+	suite.Require().NotNil(message)
+
+	actual, ok := message.(*icws.StatisticValueMessage)
+	suite.Require().Truef(ok, "Wrong Type: %s", reflect.TypeOf(message).Name())
+	suite.Require().Len(actual.Changes, 6)
+	expected := []string{
+		"inin.workgroup:NumberOfAgentsLoggedIn[ININ.People.WorkgroupStats:Workgroup=Sales]: 12",
+		"inin.workgroup:LongestInteractionWaitTime[ININ.People.WorkgroupStats:Workgroup=Sales,ININ.Queue:Interval=CurrentShift]: 1m35s",
+		"inin.workgroup:PercentAvailable: 42.5%",
+		"inin.workgroup:Name: Sales",
+		"inin.workgroup:Agents: [1, two]",
+		"inin.workgroup:Unavailable: n/a",
+	}
+	for i, change := range actual.Changes {
+		suite.Assert().Equal(expected[i], change.String())
+	}
+
+	data, err := json.Marshal(actual)
+	suite.Require().Nil(err)
+	suite.Assert().JSONEq(string(payload), string(data))
+}
+
+func (suite *MessageSuite) TestShouldTolerateUnknownStatisticValues() {
+	payload := []byte(`{"__type": "urn:inin.com:statistics:statisticValueMessage", "isDelta": true, "statisticValueChanges": [
+		{"statisticKey": {"statisticIdentifier": "inin.workgroup:Agents"}, "statisticValue": {"__type": "urn:inin.com:statistics:statisticValueList", "values": [
+			{"__type": "urn:inin.com:statistics:statisticIntValue", "value": 1},
+			{"__type": "urn:inin.com:statistics:statisticFutureValue", "value": "?"}
+		]}},
+		{"statisticKey": {"statisticIdentifier": "inin.workgroup:Future"}, "statisticValue": {"__type": "urn:inin.com:statistics:statisticFutureValue", "value": "?"}}
+	]}`)
+	message, err := icws.UnmarshalMessage(payload)
+	suite.Require().Nil(err)
+	actual, ok := message.(*icws.StatisticValueMessage)
+	suite.Require().Truef(ok, "Wrong Type: %s", reflect.TypeOf(message).Name())
+	suite.Require().Len(actual.Changes, 2)
+	suite.Assert().Equal("inin.workgroup:Agents: [1]", actual.Changes[0].String())
+	suite.Assert().Equal("inin.workgroup:Future: n/a", actual.Changes[1].String())
+}
+
 func (suite *MessageSuite) TestShouldFailUnmarshalWithWrongType() {
 	payload := []byte(`{"__type": "boggus", "userStatusList" : []}`)
 	_, err := icws.UnmarshalMessage(payload)
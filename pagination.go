@@ -0,0 +1,171 @@
+package icws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// PageOptions describes how to walk the items of a paginated endpoint
+type PageOptions struct {
+	// Context allows to cancel the walk, the Session Context is used if nil
+	Context context.Context
+	// PageSize is the number of items to get per request, PureConnect decides if 0
+	PageSize int
+	// MaxItems is the maximum number of items to walk, all items are walked if 0
+	MaxItems int
+	// Query is sent with every request
+	Query QueryOptions
+}
+
+// Cursor walks the items of a paginated endpoint, one page at a time
+//
+// Usage:
+//
+//	cursor := icws.Paginate[MyItem](session, "/configuration/things", icws.PageOptions{PageSize: 100})
+//	for cursor.Next() {
+//	  item := cursor.Item()
+//	}
+//	if err := cursor.Err(); err != nil {
+//	  ...
+//	}
+type Cursor[T any] struct {
+	session *Session
+	path    string
+	options PageOptions
+	page    []T
+	index   int
+	count   int
+	next    Range
+	started bool
+	done    bool
+	err     error
+}
+
+// Paginate creates a Cursor on the items of the given path
+//
+// The endpoint must send its items in an "items" array and its pages with a Content-Range header
+func Paginate[T any](session *Session, path string, options PageOptions) *Cursor[T] {
+	if options.Context == nil {
+		options.Context = session.Context
+	}
+	if options.Context == nil {
+		options.Context = context.Background()
+	}
+	return &Cursor[T]{
+		session: session,
+		path:    path,
+		options: options,
+		index:   -1,
+	}
+}
+
+// Next moves the Cursor to the next item, fetching the next page when needed
+//
+// returns false when there are no more items or when an error occurred
+func (cursor *Cursor[T]) Next() bool {
+	if cursor.err != nil {
+		return false
+	}
+	if cursor.options.MaxItems > 0 && cursor.count >= cursor.options.MaxItems {
+		return false
+	}
+	if err := cursor.options.Context.Err(); err != nil {
+		cursor.err = err
+		return false
+	}
+	cursor.index++
+	for cursor.index >= len(cursor.page) {
+		if cursor.done || !cursor.fetch() {
+			return false
+		}
+	}
+	cursor.count++
+	return true
+}
+
+// Item gives the current item
+func (cursor *Cursor[T]) Item() T {
+	if cursor.index < 0 || cursor.index >= len(cursor.page) {
+		var zero T
+		return zero
+	}
+	return cursor.page[cursor.index]
+}
+
+// Err gives the error that stopped the Cursor, if any
+func (cursor *Cursor[T]) Err() error {
+	return cursor.err
+}
+
+// Count tells how many items the Cursor walked so far
+func (cursor *Cursor[T]) Count() int {
+	return cursor.count
+}
+
+// ForEach calls the callback with each item, one page in memory at a time
+//
+// The walk stops at the first error returned by the callback
+func (cursor *Cursor[T]) ForEach(callback func(item T) error) error {
+	for cursor.Next() {
+		if err := callback(cursor.Item()); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// All gives all the remaining items
+func (cursor *Cursor[T]) All() ([]T, error) {
+	items := []T{}
+	err := cursor.ForEach(func(item T) error {
+		items = append(items, item)
+		return nil
+	})
+	return items, err
+}
+
+// fetch gets the next page
+func (cursor *Cursor[T]) fetch() bool {
+	headers := map[string]string{}
+	if cursor.started {
+		pageSize := cursor.options.PageSize
+		if pageSize <= 0 {
+			pageSize = cursor.next.Last - cursor.next.First + 1
+		}
+		cursor.next.First = cursor.next.Last + 1
+		cursor.next.Last = cursor.next.First + pageSize - 1
+	} else if cursor.options.PageSize > 0 {
+		cursor.next = Range{Unit: "items", First: 0, Last: cursor.options.PageSize - 1}
+	}
+	if cursor.started || cursor.options.PageSize > 0 {
+		if remaining := cursor.options.MaxItems - cursor.count; cursor.options.MaxItems > 0 && cursor.next.Last-cursor.next.First+1 > remaining {
+			cursor.next.Last = cursor.next.First + remaining - 1
+		}
+		headers["Range"] = fmt.Sprintf("items=%d-%d", cursor.next.First, cursor.next.Last)
+	}
+	cursor.started = true
+
+	data := struct {
+		Items []T `json:"items"`
+	}{} // a new struct per page, so items of a previous page cannot leak
+	response, err := cursor.session.sendContext(cursor.options.Context, http.MethodGet, cursor.path, headers, cursor.options.Query.AsQueryParameters(), nil, &data)
+	if err != nil {
+		cursor.err = err
+		return false
+	}
+	if err := cursor.options.Context.Err(); err != nil {
+		cursor.err = err
+		return false
+	}
+	cursor.page = data.Items
+	cursor.index = 0
+	received := GetRangeFromHeader(response.Headers)
+	if len(received.Unit) > 0 {
+		cursor.next = received
+	} else {
+		cursor.next.Last = cursor.next.First + len(data.Items) - 1
+	}
+	cursor.done = len(data.Items) == 0 || len(received.Unit) == 0 || received.IsAtEnd()
+	return len(data.Items) > 0
+}
@@ -0,0 +1,100 @@
+package icws_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-icws"
+)
+
+// servePages serves total users, at most 100 per page, and records the requested ranges
+func servePages(server *FakeServer, total int, ranges *[]string) {
+	rangeHeader := regexp.MustCompile(`items=(\d+)-(\d+)`)
+	server.Handlers["GET /icws/1234/configuration/users"] = func(w http.ResponseWriter, r *http.Request) {
+		first, last := 0, 99
+		if matches := rangeHeader.FindStringSubmatch(r.Header.Get("Range")); matches != nil {
+			first, _ = strconv.Atoi(matches[1])
+			last, _ = strconv.Atoi(matches[2])
+		}
+		if last-first > 99 {
+			last = first + 99
+		}
+		if last >= total {
+			last = total - 1
+		}
+		server.mutex.Lock()
+		*ranges = append(*ranges, r.Header.Get("Range"))
+		server.mutex.Unlock()
+		items := []interface{}{}
+		for i := first; i <= last; i++ {
+			items = append(items, map[string]interface{}{
+				"configurationId": map[string]string{"id": fmt.Sprintf("user%03d", i)},
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Range", fmt.Sprintf("items %d-%d/%d", first, last, total))
+		w.WriteHeader(http.StatusPartialContent)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	}
+}
+
+func (suite *SessionSuite) TestCanPaginateUsers() {
+	ranges := []string{}
+	session, _ := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		servePages(server, 250, &ranges)
+	})
+
+	users, err := session.GetUsersWithOptions(icws.QueryOptions{})
+	suite.Require().Nil(err)
+	suite.Require().Len(users, 250)
+	suite.Assert().Equal("user000", users[0].ID)
+	suite.Assert().Equal("user249", users[249].ID)
+	suite.Assert().Equal([]string{"", "items=100-199", "items=200-299"}, ranges)
+}
+
+func (suite *SessionSuite) TestCanPaginateWithPageSizeAndMaxItems() {
+	ranges := []string{}
+	session, _ := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		servePages(server, 250, &ranges)
+	})
+
+	cursor := icws.Paginate[struct {
+		Configuration struct {
+			ID string `json:"id"`
+		} `json:"configurationId"`
+	}](session, "/configuration/users", icws.PageOptions{PageSize: 40, MaxItems: 90})
+	count := 0
+	for cursor.Next() {
+		suite.Assert().Equal(fmt.Sprintf("user%03d", count), cursor.Item().Configuration.ID)
+		count++
+	}
+	suite.Require().Nil(cursor.Err())
+	suite.Assert().Equal(90, count)
+	suite.Assert().Equal([]string{"items=0-39", "items=40-79", "items=80-89"}, ranges)
+}
+
+func (suite *SessionSuite) TestShouldStopPaginatingWhenCanceled() {
+	ranges := []string{}
+	session, _ := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		servePages(server, 250, &ranges)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	count := 0
+	err := session.ForEachUser(icws.PageOptions{Context: ctx, PageSize: 50}, func(user icws.User) error {
+		count++
+		if count == 60 {
+			cancel()
+		}
+		return nil
+	})
+	suite.Require().NotNil(err)
+	suite.Assert().True(errors.Is(err, context.Canceled))
+	suite.Assert().Equal(60, count)
+	suite.Assert().Len(ranges, 2)
+}
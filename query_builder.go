@@ -0,0 +1,208 @@
+package icws
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gildas/go-errors"
+)
+
+// QueryField is a field of a PureConnect object used in a QueryCondition
+//
+// Nested fields are separated by dots, e.g.: configurationId.displayName
+type QueryField string
+
+// QueryOperator is an operator of a QueryCondition
+type QueryOperator string
+
+const (
+	EqualsOperator     QueryOperator = "eq"
+	ContainsOperator   QueryOperator = "ct"
+	StartsWithOperator QueryOperator = "sw"
+	NotOperator        QueryOperator = "not"
+	AndOperator        QueryOperator = "and"
+	OrOperator         QueryOperator = "or"
+)
+
+// QueryCondition is a condition of the where clause of a query
+//
+// Build them with Field, Where, Not and their And/Or methods:
+//
+//	condition := icws.Where(icws.Field("configurationId.displayName").StartsWith("Bob")).And(icws.Field("extension").Eq("7001"))
+//	options := icws.QueryOptions{Where: condition.AsQueryConditions()}
+type QueryCondition struct {
+	Operator   QueryOperator
+	Field      QueryField
+	Value      string
+	Conditions []QueryCondition // operands of and, or, not
+}
+
+// Field creates a QueryField
+func Field(name string) QueryField {
+	return QueryField(name)
+}
+
+// Where starts a where clause with the given condition
+func Where(condition QueryCondition) QueryCondition {
+	return condition
+}
+
+// Not negates a condition
+func Not(condition QueryCondition) QueryCondition {
+	return QueryCondition{Operator: NotOperator, Conditions: []QueryCondition{condition}}
+}
+
+// Eq creates a condition that matches when the field equals the value
+func (field QueryField) Eq(value interface{}) QueryCondition {
+	return QueryCondition{Operator: EqualsOperator, Field: field, Value: fmt.Sprint(value)}
+}
+
+// Contains creates a condition that matches when the field contains the value
+func (field QueryField) Contains(value string) QueryCondition {
+	return QueryCondition{Operator: ContainsOperator, Field: field, Value: value}
+}
+
+// StartsWith creates a condition that matches when the field starts with the value
+func (field QueryField) StartsWith(value string) QueryCondition {
+	return QueryCondition{Operator: StartsWithOperator, Field: field, Value: value}
+}
+
+// And combines this condition with others, all must match
+func (condition QueryCondition) And(others ...QueryCondition) QueryCondition {
+	return condition.combine(AndOperator, others)
+}
+
+// Or combines this condition with others, at least one must match
+func (condition QueryCondition) Or(others ...QueryCondition) QueryCondition {
+	return condition.combine(OrOperator, others)
+}
+
+// Fields gives the fields used by the condition
+func (condition QueryCondition) Fields() []QueryField {
+	if len(condition.Field) > 0 {
+		return []QueryField{condition.Field}
+	}
+	fields := []QueryField{}
+	for _, operand := range condition.Conditions {
+		fields = append(fields, operand.Fields()...)
+	}
+	return fields
+}
+
+// AsQueryConditions gives the QueryConditions to use in QueryOptions.Where
+//
+// An empty condition gives no QueryConditions.
+func (condition QueryCondition) AsQueryConditions() QueryConditions {
+	clause := condition.String()
+	if len(clause) == 0 {
+		return QueryConditions{}
+	}
+	if condition.Operator == OrOperator {
+		// QueryOptions joins its conditions with commas, which means and
+		return QueryConditions{"(" + clause + ")"}
+	}
+	return QueryConditions{clause}
+}
+
+// String gets the ICWS representation of the condition
+//
+// Values are single-quoted, backslashes and single quotes are escaped with a backslash.
+// Conditions are combined with "," (and) and "|" (or), and grouped with parentheses.
+// Conditions without a field or an operator (e.g. a zero QueryCondition) are left out.
+//
+// implements fmt.Stringer
+func (condition QueryCondition) String() string {
+	switch condition.Operator {
+	case AndOperator, OrOperator:
+		separator := ","
+		if condition.Operator == OrOperator {
+			separator = "|"
+		}
+		operands := make([]string, 0, len(condition.Conditions))
+		for _, operand := range condition.Conditions {
+			clause := operand.String()
+			if len(clause) == 0 {
+				continue
+			}
+			if operand.Operator != condition.Operator && (operand.Operator == AndOperator || operand.Operator == OrOperator) {
+				clause = "(" + clause + ")"
+			}
+			operands = append(operands, clause)
+		}
+		return strings.Join(operands, separator)
+	case NotOperator:
+		if len(condition.Conditions) == 0 {
+			return ""
+		}
+		clause := condition.Conditions[0].String()
+		if len(clause) == 0 {
+			return ""
+		}
+		return "not(" + clause + ")"
+	default:
+		if len(condition.Field) == 0 || len(condition.Operator) == 0 {
+			return ""
+		}
+		return string(condition.Field) + " " + string(condition.Operator) + " " + quoteQueryValue(condition.Value)
+	}
+}
+
+// ValidateQuery verifies the fields of a condition exist in the configuration objects of type T
+func ValidateQuery[T ConfigurationObject](condition QueryCondition) error {
+	var object T
+	known := knownQueryFields(reflect.TypeOf(object))
+	for _, field := range condition.Fields() {
+		if !known[string(field)] {
+			return errors.ArgumentInvalid.With("field", string(field))
+		}
+	}
+	return nil
+}
+
+func (condition QueryCondition) combine(operator QueryOperator, others []QueryCondition) QueryCondition {
+	operands := make([]QueryCondition, 0, len(others)+1)
+	if condition.Operator == operator {
+		operands = append(operands, condition.Conditions...)
+	} else {
+		operands = append(operands, condition)
+	}
+	operands = append(operands, others...)
+	return QueryCondition{Operator: operator, Conditions: operands}
+}
+
+func quoteQueryValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// knownQueryFields gives the JSON paths of the fields of a type
+func knownQueryFields(objectType reflect.Type) map[string]bool {
+	fields := map[string]bool{}
+	collectQueryFields(objectType, "", fields)
+	return fields
+}
+
+func collectQueryFields(objectType reflect.Type, prefix string, fields map[string]bool) {
+	for objectType.Kind() == reflect.Ptr || objectType.Kind() == reflect.Slice {
+		objectType = objectType.Elem()
+	}
+	if objectType.Kind() != reflect.Struct || reflect.PointerTo(objectType).Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()) {
+		return
+	}
+	for i := 0; i < objectType.NumField(); i++ {
+		field := objectType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+		fields[prefix+name] = true
+		collectQueryFields(field.Type, prefix+name+".", fields)
+	}
+}
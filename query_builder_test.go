@@ -0,0 +1,69 @@
+package icws_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-icws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+func TestQueryBuilderGolden(t *testing.T) {
+	cases := map[string]icws.QueryCondition{
+		"equals":      icws.Where(icws.Field("extension").Eq(7001)),
+		"starts_with": icws.Where(icws.Field("configurationId.displayName").StartsWith("Bob")),
+		"and":         icws.Where(icws.Field("configurationId.displayName").StartsWith("Bob")).And(icws.Field("extension").Contains("70")),
+		"or":          icws.Where(icws.Field("configurationId.id").Eq("agent")).Or(icws.Field("configurationId.id").Eq("supervisor")),
+		"not":         icws.Not(icws.Field("configurationId.id").Contains("test")),
+		"grouping":    icws.Where(icws.Field("extension").StartsWith("7")).And(icws.Field("configurationId.id").Eq("a").Or(icws.Field("configurationId.id").Eq("b")), icws.Not(icws.Field("mwiEnabled").Eq(true))),
+		"flattening":  icws.Where(icws.Field("a").Eq("1")).And(icws.Field("b").Eq("2")).And(icws.Field("c").Eq("3")),
+		"escaping":    icws.Where(icws.Field("configurationId.displayName").Eq(`O'Brien, Bob (\temp|)`)),
+		"empty":       icws.Where(icws.QueryCondition{}).And(icws.Field("extension").Eq("7001"), icws.Not(icws.QueryCondition{}), icws.Field("").Eq("x")),
+	}
+	for name, condition := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(".", "testdata", "query", name+".golden")
+			actual := strings.Join(condition.AsQueryConditions(), "\n") + "\n"
+			if *updateGolden {
+				require.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+				require.Nil(t, os.WriteFile(path, []byte(actual), 0644))
+			}
+			expected, err := os.ReadFile(path)
+			require.Nil(t, err, "Failed to read golden file %s, run the tests with -update", path)
+			assert.Equal(t, string(expected), actual)
+		})
+	}
+}
+
+func TestCanValidateQueryFields(t *testing.T) {
+	condition := icws.Where(icws.Field("configurationId.displayName").StartsWith("Bob")).And(icws.Field("personalInformationProperties.surname").Eq("Smith"))
+	assert.Nil(t, icws.ValidateQuery[icws.UserConfiguration](condition))
+
+	err := icws.ValidateQuery[icws.WorkgroupConfiguration](condition)
+	require.NotNil(t, err)
+	assert.True(t, errors.Is(err, errors.ArgumentInvalid))
+}
+
+func TestCanUseQueryBuilderInQueryOptions(t *testing.T) {
+	options := icws.QueryOptions{
+		Where: icws.Where(icws.Field("extension").Eq("7001")).AsQueryConditions(),
+	}
+	assert.Equal(t, "extension eq '7001'", options.AsQueryParameters()["where"])
+}
+
+func TestShouldLeaveOutEmptyQueryConditions(t *testing.T) {
+	assert.Empty(t, icws.QueryCondition{}.String())
+	assert.Empty(t, icws.QueryCondition{}.AsQueryConditions())
+	assert.Empty(t, icws.Where(icws.QueryCondition{}).Or(icws.Not(icws.QueryCondition{})).AsQueryConditions())
+
+	options := icws.QueryOptions{Where: icws.QueryCondition{}.AsQueryConditions()}
+	_, found := options.AsQueryParameters()["where"]
+	assert.False(t, found, "An empty condition should not give a where clause")
+}
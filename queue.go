@@ -0,0 +1,79 @@
+package icws
+
+import (
+	"strconv"
+)
+
+// QueueType describes the type of a PureConnect queue
+type QueueType int
+
+const (
+	SystemQueue    QueueType = 0
+	UserQueue      QueueType = 1
+	WorkgroupQueue QueueType = 2
+	StationQueue   QueueType = 3
+)
+
+// QueueID identifies a PureConnect queue
+type QueueID struct {
+	Type QueueType `json:"queueType"`
+	Name string    `json:"queueName"`
+}
+
+// DefaultQueueAttributes are the Interaction attributes watched when a QueueSubscription does not give any
+var DefaultQueueAttributes = []string{
+	StateAttribute,
+	DirectionAttribute,
+	RemoteNameAttribute,
+	RemoteAddressAttribute,
+	ObjectTypeAttribute,
+	WorkgroupAttribute,
+	UserNameAttribute,
+	MutedAttribute,
+	InitiationAttribute,
+}
+
+// NewUserQueue creates a QueueID for the queue of the given user
+func NewUserQueue(userID string) QueueID {
+	return QueueID{Type: UserQueue, Name: userID}
+}
+
+// NewWorkgroupQueue creates a QueueID for the queue of the given workgroup
+func NewWorkgroupQueue(workgroup string) QueueID {
+	return QueueID{Type: WorkgroupQueue, Name: workgroup}
+}
+
+// NewStationQueue creates a QueueID for the queue of the given station
+func NewStationQueue(station string) QueueID {
+	return QueueID{Type: StationQueue, Name: station}
+}
+
+// NewSystemQueue creates a QueueID for the given system queue
+func NewSystemQueue(name string) QueueID {
+	return QueueID{Type: SystemQueue, Name: name}
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (queueType QueueType) String() string {
+	switch queueType {
+	case SystemQueue:
+		return "system"
+	case UserQueue:
+		return "user"
+	case WorkgroupQueue:
+		return "workgroup"
+	case StationQueue:
+		return "station"
+	default:
+		return "queue type " + strconv.Itoa(int(queueType))
+	}
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (queue QueueID) String() string {
+	return queue.Type.String() + ":" + queue.Name
+}
@@ -0,0 +1,109 @@
+package icws
+
+import (
+	"sort"
+	"sync"
+)
+
+// QueueCache keeps the Interactions of queue subscriptions up to date
+//
+// The cache applies the QueueMessage deltas in the order they are received
+// and gives consistent snapshots of the Interactions per subscription.
+type QueueCache struct {
+	messageFeed[QueueMessage]
+	queues map[string]map[string]Interaction
+	mutex  sync.RWMutex
+}
+
+// NewQueueCache creates a new QueueCache
+//
+// If session is not nil, the QueueMessage it receives are applied to the cache.
+func NewQueueCache(session *Session) *QueueCache {
+	cache := &QueueCache{queues: map[string]map[string]Interaction{}}
+	if session != nil {
+		cache.start(session, cache.Apply)
+	}
+	return cache
+}
+
+// Apply applies the changes of a QueueMessage
+func (cache *QueueCache) Apply(message QueueMessage) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	queue, found := cache.queues[message.SubscriptionID]
+	if !found || !message.IsDelta {
+		queue = map[string]Interaction{}
+		cache.queues[message.SubscriptionID] = queue
+	}
+	for _, interaction := range message.InteractionsAdded {
+		queue[interaction.ID] = interaction.clone()
+	}
+	for _, interaction := range message.InteractionsChanged {
+		current, found := queue[interaction.ID]
+		if !found {
+			queue[interaction.ID] = interaction.clone()
+			continue
+		}
+		for name, value := range interaction.Attributes {
+			current.Attributes[name] = value
+		}
+	}
+	for _, interactionID := range message.InteractionsRemoved {
+		delete(queue, interactionID)
+	}
+}
+
+// Snapshot gives the Interactions of a subscription, sorted by their ID
+//
+// The Interactions are copies, they are not modified by later changes
+func (cache *QueueCache) Snapshot(subscriptionID string) []Interaction {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	queue := cache.queues[subscriptionID]
+	interactions := make([]Interaction, 0, len(queue))
+	for _, interaction := range queue {
+		interactions = append(interactions, interaction.clone())
+	}
+	sort.Slice(interactions, func(i, j int) bool { return interactions[i].ID < interactions[j].ID })
+	return interactions
+}
+
+// Get gives an Interaction of a subscription
+func (cache *QueueCache) Get(subscriptionID, interactionID string) (Interaction, bool) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	interaction, found := cache.queues[subscriptionID][interactionID]
+	if !found {
+		return Interaction{}, false
+	}
+	return interaction.clone(), true
+}
+
+// Count tells how many Interactions a subscription contains
+func (cache *QueueCache) Count(subscriptionID string) int {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	return len(cache.queues[subscriptionID])
+}
+
+// Forget removes the Interactions of a subscription from the cache
+func (cache *QueueCache) Forget(subscriptionID string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	delete(cache.queues, subscriptionID)
+}
+
+// Close stops feeding the cache from its Session
+func (cache *QueueCache) Close() {
+	cache.stop()
+}
+
+func (interaction Interaction) clone() Interaction {
+	attributes := make(InteractionAttributes, len(interaction.Attributes))
+	for name, value := range interaction.Attributes {
+		attributes[name] = value
+	}
+	return Interaction{ID: interaction.ID, Attributes: attributes}
+}
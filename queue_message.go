@@ -0,0 +1,143 @@
+package icws
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/gildas/go-errors"
+)
+
+// QueueMessage describes the changes of the Interactions of the queues of a subscription
+//
+// When IsDelta is false, the message contains all the Interactions of the subscription in InteractionsAdded
+type QueueMessage struct {
+	SubscriptionID      string        `json:"subscriptionId"`
+	IsDelta             bool          `json:"isDelta"`
+	InteractionsAdded   []Interaction `json:"interactionsAdded,omitempty"`
+	InteractionsChanged []Interaction `json:"interactionsChanged,omitempty"`
+	InteractionsRemoved []string      `json:"interactionsRemoved,omitempty"`
+}
+
+// QueueSubscription describes a Queue Subscription Request
+type QueueSubscription struct {
+	Queues     []QueueID `json:"queueIds"`
+	Attributes []string  `json:"attributeNames"`
+}
+
+func init() {
+	messageRegistry.Add(QueueMessage{})
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (message QueueMessage) GetType() string {
+	return "urn:inin.com:queues:queueContentsMessage"
+}
+
+// Subscribe subscribe a Session to this type of messages
+//
+// The SubscriptionID of the message identifies the subscription.
+// If the payload is a QueueSubscription without Attributes, DefaultQueueAttributes are used.
+//
+// implements Subscriber
+func (message QueueMessage) Subscribe(context context.Context, session *Session, payload interface{}) error {
+	if subscription, ok := payload.(QueueSubscription); ok && len(subscription.Attributes) == 0 {
+		subscription.Attributes = DefaultQueueAttributes
+		payload = subscription
+	}
+	return session.sendPutContext(context, message.path(), payload, nil)
+}
+
+// Subscribe subscribe a Session to this type of messages
+//
+// implements Unsubscriber
+func (message QueueMessage) Unsubscribe(context context.Context, session *Session) error {
+	return session.sendDeleteContext(context, message.path())
+}
+
+// SubscribeQueues subscribes the Session to the Interactions of the given queues
+//
+// The subscriptionID identifies the subscription in the QueueMessage the Session will receive.
+func (session *Session) SubscribeQueues(subscriptionID string, queues []QueueID, attributes ...string) (*SubscriptionHandle, error) {
+	return session.SubscribeQueuesContext(session.Context, subscriptionID, queues, attributes...)
+}
+
+// SubscribeQueuesContext subscribes the Session to the Interactions of the given queues
+//
+// The context cancels the request
+func (session *Session) SubscribeQueuesContext(context context.Context, subscriptionID string, queues []QueueID, attributes ...string) (*SubscriptionHandle, error) {
+	return session.SubscribeWithHandleContext(context, QueueMessage{SubscriptionID: subscriptionID}, QueueSubscription{
+		Queues:     queues,
+		Attributes: attributes,
+	})
+}
+
+// UnsubscribeQueues unsubscribes the Session from the given subscription
+func (session *Session) UnsubscribeQueues(subscriptionID string) error {
+	return session.UnsubscribeQueuesContext(session.Context, subscriptionID)
+}
+
+// UnsubscribeQueuesContext unsubscribes the Session from the given subscription
+//
+// The context cancels the request
+func (session *Session) UnsubscribeQueuesContext(context context.Context, subscriptionID string) error {
+	return session.UnsubscribeContext(context, QueueMessage{SubscriptionID: subscriptionID})
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (message QueueMessage) String() string {
+	return "Queue " + message.SubscriptionID +
+		": added " + strconv.Itoa(len(message.InteractionsAdded)) +
+		", changed " + strconv.Itoa(len(message.InteractionsChanged)) +
+		", removed " + strconv.Itoa(len(message.InteractionsRemoved))
+}
+
+// GetSubscriptionID tells the ID of the subscription
+//
+// implements IdentifiedSubscription
+func (message QueueMessage) GetSubscriptionID() string {
+	return message.SubscriptionID
+}
+
+func (message QueueMessage) path() string {
+	return "/messaging/subscriptions/queues/" + url.PathEscape(message.SubscriptionID)
+}
+
+// MarshalJSON marshals into JSON
+//
+// implements json.Marshaler
+func (message QueueMessage) MarshalJSON() ([]byte, error) {
+	type surrogate QueueMessage
+	data, err := json.Marshal(struct {
+		Type string `json:"__type"`
+		surrogate
+	}{
+		Type:      message.GetType(),
+		surrogate: surrogate(message),
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// implements json.Unmarshaler
+func (message *QueueMessage) UnmarshalJSON(payload []byte) (err error) {
+	type surrogate QueueMessage
+	var inner struct {
+		Type string `json:"__type"`
+		surrogate
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	if inner.Type != (QueueMessage{}.GetType()) {
+		return errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("__type", inner.Type))
+	}
+	*message = QueueMessage(inner.surrogate)
+	return nil
+}
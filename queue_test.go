@@ -0,0 +1,65 @@
+package icws_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gildas/go-icws"
+)
+
+func (suite *SessionSuite) TestCanApplyQueueDeltas() {
+	cache := icws.NewQueueCache(nil)
+	cache.Apply(icws.QueueMessage{
+		SubscriptionID: "agent",
+		IsDelta:        false,
+		InteractionsAdded: []icws.Interaction{
+			{ID: "1001", Attributes: icws.InteractionAttributes{"Eic_State": "A"}},
+			{ID: "1002", Attributes: icws.InteractionAttributes{"Eic_State": "C"}},
+		},
+	})
+	suite.Assert().Equal(2, cache.Count("agent"))
+
+	snapshot := cache.Snapshot("agent")
+	cache.Apply(icws.QueueMessage{
+		SubscriptionID:      "agent",
+		IsDelta:             true,
+		InteractionsAdded:   []icws.Interaction{{ID: "1003", Attributes: icws.InteractionAttributes{"Eic_State": "O"}}},
+		InteractionsChanged: []icws.Interaction{{ID: "1001", Attributes: icws.InteractionAttributes{"Eic_State": "C"}}},
+		InteractionsRemoved: []string{"1002"},
+	})
+	suite.Require().Len(snapshot, 2)
+	suite.Assert().Equal(icws.AlertingState, snapshot[0].State(), "Snapshots should not change")
+
+	snapshot = cache.Snapshot("agent")
+	suite.Require().Len(snapshot, 2)
+	suite.Assert().Equal("1001", snapshot[0].ID)
+	suite.Assert().Equal(icws.ConnectedState, snapshot[0].State())
+	suite.Assert().Equal("1003", snapshot[1].ID)
+
+	cache.Apply(icws.QueueMessage{SubscriptionID: "agent", IsDelta: false})
+	suite.Assert().Equal(0, cache.Count("agent"))
+}
+
+func (suite *SessionSuite) TestCanCacheQueueContents() {
+	session, server := suite.NewConnectedSession()
+
+	cache := icws.NewQueueCache(session)
+	defer cache.Close()
+	_, err := session.SubscribeQueues("mine", []icws.QueueID{icws.NewUserQueue("agent")})
+	suite.Require().Nil(err)
+	suite.Assert().Equal(1, server.Count(http.MethodPut, "/icws/1234/messaging/subscriptions/queues/mine"))
+	suite.Assert().JSONEq(
+		`{"queueIds": [{"queueType": 1, "queueName": "agent"}], "attributeNames": ["Eic_State", "Eic_CallDirection", "Eic_RemoteName", "Eic_RemoteAddress", "Eic_ObjectType", "Eic_WorkgroupName", "Eic_UserName", "Eic_Muted", "Eic_InitiationTime"]}`,
+		server.Payload(http.MethodPut, "/icws/1234/messaging/subscriptions/queues/mine"),
+	)
+
+	server.StreamEvents <- "data: {\"__type\": \"urn:inin.com:queues:queueContentsMessage\", \"subscriptionId\": \"mine\", \"isDelta\": false, \"interactionsAdded\": [{\"interactionId\": \"1001\", \"attributes\": {\"Eic_State\": \"A\"}}]}\n\n"
+	server.StreamEvents <- "data: {\"__type\": \"urn:inin.com:queues:queueContentsMessage\", \"subscriptionId\": \"mine\", \"isDelta\": true, \"interactionsChanged\": [{\"interactionId\": \"1001\", \"attributes\": {\"Eic_State\": \"C\"}}]}\n\n"
+	suite.Require().Eventually(func() bool {
+		interaction, found := cache.Get("mine", "1001")
+		return found && interaction.IsConnected()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	suite.Require().Nil(session.UnsubscribeQueues("mine"))
+	suite.Assert().Equal(1, server.Count(http.MethodDelete, "/icws/1234/messaging/subscriptions/queues/mine"))
+}
@@ -1,16 +1,20 @@
 package icws
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gildas/go-errors"
 	"github.com/gildas/go-request"
 )
 
-func (session Session) endpoint(path string) (endpoint *url.URL, err error) {
+func (session *Session) endpoint(path string) (endpoint *url.URL, err error) {
+	session.mutex.RLock()
+	defer session.mutex.RUnlock()
 	if session.APIRoot == nil {
 		return nil, errors.WrapErrors(errors.CreationFailed.With("endpoint", path), errors.ArgumentMissing.With("apiRoot"))
 	}
@@ -23,61 +27,144 @@ func (session Session) endpoint(path string) (endpoint *url.URL, err error) {
 }
 
 func (session *Session) sendPost(path string, payload interface{}, results interface{}) error {
-	_, err := session.send(http.MethodPost, path, nil, nil, payload, results)
-	return err
+	return session.sendPostContext(session.Context, path, payload, results)
 }
 
 func (session *Session) sendGet(path string, results interface{}) error {
-	_, err := session.send(http.MethodGet, path, nil, nil, nil, results)
-	return err
+	return session.sendGetContext(session.Context, path, results)
 }
 
 func (session *Session) sendPut(path string, payload interface{}, results interface{}) error {
-	_, err := session.send(http.MethodPut, path, nil, nil, payload, results)
-	return err
+	return session.sendPutContext(session.Context, path, payload, results)
 }
 
 func (session *Session) sendDelete(path string) error {
-	_, err := session.send(http.MethodDelete, path, nil, nil, nil, nil)
+	return session.sendDeleteContext(session.Context, path)
+}
+
+func (session *Session) sendPostContext(context context.Context, path string, payload interface{}, results interface{}) error {
+	_, err := session.sendContext(context, http.MethodPost, path, nil, nil, payload, results)
+	return err
+}
+
+func (session *Session) sendGetContext(context context.Context, path string, results interface{}) error {
+	_, err := session.sendContext(context, http.MethodGet, path, nil, nil, nil, results)
+	return err
+}
+
+func (session *Session) sendPutContext(context context.Context, path string, payload interface{}, results interface{}) error {
+	_, err := session.sendContext(context, http.MethodPut, path, nil, nil, payload, results)
+	return err
+}
+
+func (session *Session) sendDeleteContext(context context.Context, path string) error {
+	_, err := session.sendContext(context, http.MethodDelete, path, nil, nil, nil, nil)
 	return err
 }
 
 func (session *Session) send(method, path string, headers map[string]string, queryParameters map[string]string, payload interface{}, results interface{}) (response *request.Content, err error) {
+	return session.sendContext(session.Context, method, path, headers, queryParameters, payload, results)
+}
+
+// sendContext sends the HTTP request to PureConnect, connecting the Session if needed
+//
+// The request is canceled when the context is done.
+// The Session recovers from switchovers with its own Context, not with the given one,
+// unless the context comes from withoutRecovery.
+func (session *Session) sendContext(context context.Context, method, path string, headers map[string]string, queryParameters map[string]string, payload interface{}, results interface{}) (response *request.Content, err error) {
 	log := session.Logger.Child(nil, "send_"+strings.ToLower(method))
 
-	if !session.IsConnected() && session.Status != ConnectingStatus && len(session.Token) == 0 {
-		if err = session.Connect(); err != nil {
+	session.mutex.RLock()
+	mustConnect := !session.isConnected() && (session.Status == ConnectingStatus || len(session.Token) == 0)
+	sessionID := session.ID
+	session.mutex.RUnlock()
+	if mustConnect {
+		if err = session.ConnectContext(context); err != nil {
 			return nil, err
 		}
+		sessionID = session.GetID()
 	}
+	response, err = session.sendRequest(context, method, path, headers, queryParameters, payload, results)
+	if err != nil {
+		if session.SwitchoverRecovery && canRecover(context) && session.mustRecover(context, err, response) {
+			log.Warnf("Server is not available anymore, recovering from switchover")
+			recovered, err := session.recover(sessionID, getAlternateHosts(response))
+			if err != nil {
+				return response, SwitchoverFailed.Wrap(err)
+			}
+			if recovered && isIdempotent(method) {
+				// The request is replayed only once, a Session that keeps failing is not recovered again
+				log.Infof("Replaying HTTP %s %s", method, path)
+				response, err = session.sendRequest(context, method, path, headers, queryParameters, payload, results)
+				if err != nil && isSwitchoverError(err) {
+					return response, SwitchoverFailed.Wrap(err)
+				}
+				return response, err
+			}
+		}
+		return response, err
+	}
+	return response, nil
+}
+
+// sendRequest sends the HTTP request to PureConnect
+//
+// Unlike send, it does not connect the Session and does not recover from switchovers
+func (session *Session) sendRequest(context context.Context, method, path string, headers map[string]string, queryParameters map[string]string, payload interface{}, results interface{}) (response *request.Content, err error) {
+	log := session.Logger.Child(nil, "send_"+strings.ToLower(method))
+
 	endpoint, err := session.endpoint(path)
 	if err != nil {
 		return nil, err
 	}
-	if headers == nil {
-		headers = map[string]string{}
+	token, cookies := session.getCredentials()
+	requestHeaders := map[string]string{}
+	for key, value := range headers {
+		requestHeaders[key] = value
 	}
-	headers["Accept-Language"] = session.Language
-	if len(session.Token) > 0 {
-		headers["ININ-ICWS-CSRF-Token"] = session.Token
+	requestHeaders["Accept-Language"] = session.Language
+	if len(token) > 0 {
+		requestHeaders["ININ-ICWS-CSRF-Token"] = token
+	}
+	timeout, attempts := session.RequestTimeout, session.RequestAttempts
+	if context != nil {
+		if err = context.Err(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if deadline, ok := context.Deadline(); ok {
+			if timeout <= 0 {
+				timeout = request.DefaultTimeout
+			}
+			if remaining := time.Until(deadline); remaining <= timeout {
+				// There is no time left to retry once the deadline is reached
+				timeout, attempts = remaining, 1
+			}
+		}
 	}
 	response, err = request.Send(&request.Options{
-		Context:    session.Context,
+		Context:    context,
 		UserAgent:  "GENESYS ICWS GO Client v" + VERSION,
 		Method:     method,
 		URL:        endpoint,
-		Headers:    headers,
-		Cookies:    session.Cookies,
+		Headers:    requestHeaders,
+		Cookies:    cookies,
 		Parameters: queryParameters,
 		Payload:    payload,
+		Transport:  session.transport,
+		Timeout:    timeout,
+		Attempts:   attempts,
 		Logger:     log,
 	}, results)
 	if err != nil {
-		// TODO: On HTTP 503, we receive a list of alternate hosts that we should connect to
+		if context != nil && context.Err() != nil {
+			return response, errors.WithStack(context.Err())
+		}
 		return response, err
 	}
 	if len(response.Cookies) > 0 {
+		session.mutex.Lock()
 		session.Cookies = response.Cookies
+		session.mutex.Unlock()
 	}
 	return response, nil
 }
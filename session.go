@@ -2,19 +2,30 @@ package icws
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gildas/go-core"
 	"github.com/gildas/go-errors"
 	"github.com/gildas/go-logger"
+	"github.com/gildas/go-request"
 )
 
 // Session describes a session connected to a PureConnect server
+//
+// A Session is safe for concurrent use. Its exported fields are updated by the Session
+// while connecting and should be read via its methods (GetID, GetStatus, IsConnected, ...)
+// when the Session is shared between goroutines.
+//
+// Breaking change: as a Session holds its own locks, all its methods have pointer receivers
+// and a Session must not be copied. Pass a *Session around: json.Marshal, core.Identifiable
+// and fmt.Stringer work with a *Session, not with a Session value.
 type Session struct {
 	ID                   string                  `json:"id"`
 	Token                string                  `json:"token"`
@@ -27,12 +38,59 @@ type Session struct {
 	StationSettings      StationSettings         `json:"stationSettings"`
 	Status               SessionStatus           `json:"status"`
 	Features             []SessionFeature        `json:"features"`
-	Subscriptions        map[string]Subscription `json:"-"`
+	Subscriptions        map[string]Subscription `json:"-"` // keyed by type, and subscription ID for an IdentifiedSubscription
+	subscriptionPayloads map[string]interface{}  `json:"-"`
+	watchedUsers         map[string]int          `json:"-"` // Users whose status is watched, with how many watchers
 	eventStream          *EventStream            `json:"-"`
+	events               chan EventSource        `json:"-"` // the chan given by Events
+	keepAliveCancel      context.CancelFunc      `json:"-"`
+	keepAliveDone        chan struct{}           `json:"-"`
+	connecting           *flight                 `json:"-"`
+	recovering           *flight                 `json:"-"`
+	dispatcher           dispatcher              `json:"-"`
+	transport            *http.Transport         `json:"-"` // shared by all the requests
+	streamTransport      *http.Transport         `json:"-"` // used by the Server-Sent Events, built once per Session
+	mutex                sync.RWMutex            `json:"-"`
 	Logger               *logger.Logger          `json:"-"`
 	SessionOptions
 }
 
+// flight describes an operation shared by concurrent callers
+type flight struct {
+	sessionID string // the PureConnect session the operation started from
+	done      chan struct{}
+	err       error
+}
+
+func newFlight(sessionID string) *flight {
+	return &flight{sessionID: sessionID, done: make(chan struct{})}
+}
+
+// wait waits for the operation to complete and returns its error
+func (f *flight) wait() error {
+	<-f.done
+	return f.err
+}
+
+// waitContext waits for the operation to complete or for the context to be done
+func (f *flight) waitContext(context context.Context) error {
+	if context == nil {
+		return f.wait()
+	}
+	select {
+	case <-f.done:
+		return f.err
+	case <-context.Done():
+		return errors.WithStack(context.Err())
+	}
+}
+
+// complete completes the operation with the given error
+func (f *flight) complete(err error) {
+	f.err = err
+	close(f.done)
+}
+
 // SessionOptions describes the options of a Session
 //
 // To give a Logger to the Session, pass it to the Context
@@ -47,8 +105,113 @@ type SessionOptions struct {
 	Application  string            `json:"applicationName"`
 	Language     string            `json:"language"`
 	TokenUpdated chan UpdatedToken `json:"-"`
+
+	// MaxSwitchoverAttempts is the maximum number of times Connect follows
+	// the alternate host list sent by a PureConnect Server with an HTTP 503
+	//
+	// Default: DefaultMaxSwitchoverAttempts
+	MaxSwitchoverAttempts int `json:"-"`
+
+	// SwitchoverRecovery tells the Session to recover from a switchover while connected
+	//
+	// When a request fails with an HTTP 503 or a connection reset, the Session reconnects
+	// to the alternate host, restores its subscriptions and station, and replays the request
+	// if it is idempotent.
+	SwitchoverRecovery bool `json:"-"`
+
+	// EventReconnectDelay is the initial delay before reconnecting the Server-Sent Events stream
+	//
+	// PureConnect can change it via the "retry" field. Default: DefaultEventStreamReconnectDelay
+	EventReconnectDelay time.Duration `json:"-"`
+
+	// EventMaxReconnectDelay is the maximum delay between 2 reconnections of the Server-Sent Events stream
+	//
+	// Default: DefaultEventStreamMaxReconnectDelay
+	EventMaxReconnectDelay time.Duration `json:"-"`
+
+	// EventMaxReconnectAttempts is the maximum number of reconnections of the Server-Sent Events stream
+	//
+	// Default: 0, no limit
+	EventMaxReconnectAttempts int `json:"-"`
+
+	// DisableEventReconnect tells the Session to not reconnect the Server-Sent Events stream
+	DisableEventReconnect bool `json:"-"`
+
+	// MessagePollingInterval is the interval between 2 polls of the messages
+	//
+	// Messages are polled when the PureConnect Server does not support Server-Sent Events.
+	// Default: DefaultMessagePollingInterval
+	MessagePollingInterval time.Duration `json:"-"`
+
+	// ForceMessagePolling tells the Session to poll the messages even if the PureConnect Server supports Server-Sent Events
+	ForceMessagePolling bool `json:"-"`
+
+	// KeepAliveInterval is the interval between 2 checks of the PureConnect session
+	//
+	// When the PureConnect session expires, the Session status becomes DisconnectedStatus
+	// and a SessionExpiredMessage is sent to the Events chans.
+	// Default: 0, no keepalive
+	KeepAliveInterval time.Duration `json:"-"`
+
+	// KeepAliveReconnect tells the Session to reconnect and restore its subscriptions when its PureConnect session expired
+	//
+	// A SessionReconnectedMessage is sent to the Events chans once reconnected
+	KeepAliveReconnect bool `json:"-"`
+
+	// EventBufferSize is the size of the buffer of the chans given by Events
+	//
+	// Default: DefaultEventBufferSize
+	EventBufferSize int `json:"-"`
+
+	// EventOverflow tells what to do when the buffer of a chan given by Events is full
+	//
+	// Default: BlockOnOverflow
+	EventOverflow EventOverflowPolicy `json:"-"`
+
+	// SlowHandlerThreshold is the duration after which a Message handler is reported as slow
+	//
+	// Default: DefaultSlowHandlerThreshold
+	SlowHandlerThreshold time.Duration `json:"-"`
+
+	// Transport is the HTTP transport used to send requests and to receive the Server-Sent Events
+	//
+	// It is cloned before TLSConfig, InsecureSkipVerify and Proxy are applied.
+	// Default: a clone of http.DefaultTransport
+	Transport *http.Transport `json:"-"`
+
+	// TLSConfig configures the TLS connections to PureConnect, e.g. with an internal CA bundle or client certificates
+	TLSConfig *tls.Config `json:"-"`
+
+	// InsecureSkipVerify tells the Session to not verify the certificates of PureConnect
+	//
+	// Only use this in labs, this makes the Session vulnerable to man-in-the-middle attacks
+	InsecureSkipVerify bool `json:"-"`
+
+	// Proxy is the URL of the HTTP proxy to go through
+	//
+	// Default: the proxy of Transport, http.ProxyFromEnvironment for the default transport
+	Proxy *url.URL `json:"-"`
+
+	// RequestTimeout is the maximum duration of each attempt of a request sent to PureConnect
+	//
+	// For the Server-Sent Events, it is the maximum duration to wait for the response headers.
+	// Default: request.DefaultTimeout
+	RequestTimeout time.Duration `json:"-"`
+
+	// RequestAttempts is the number of times a request is attempted when it times out
+	//
+	// Default: request.DefaultAttempts
+	RequestAttempts int `json:"-"`
+
+	// SkipStationLicenses tells ConnectStation not to check the licenses of the User first
+	//
+	// See CheckStationLicenses. Default: false, the licenses are checked
+	SkipStationLicenses bool `json:"-"`
 }
 
+// DefaultMaxSwitchoverAttempts is the default maximum number of switchovers followed by Connect
+const DefaultMaxSwitchoverAttempts = 5
+
 // UpdatedToken describes the event sent to a chan letting applications know about new Token
 type UpdatedToken struct {
 	Token     string          `json:"token"`
@@ -76,58 +239,179 @@ func NewSession(options SessionOptions) *Session {
 	if len(options.Language) == 0 {
 		options.Language = "en-us"
 	}
+	if options.MaxSwitchoverAttempts <= 0 {
+		options.MaxSwitchoverAttempts = DefaultMaxSwitchoverAttempts
+	}
+	if options.MessagePollingInterval <= 0 {
+		options.MessagePollingInterval = DefaultMessagePollingInterval
+	}
+	if options.EventBufferSize <= 0 {
+		options.EventBufferSize = DefaultEventBufferSize
+	}
+	if options.SlowHandlerThreshold <= 0 {
+		options.SlowHandlerThreshold = DefaultSlowHandlerThreshold
+	}
+	eventStream := NewEventStream()
+	eventStream.keepOpen = options.SwitchoverRecovery || options.KeepAliveInterval > 0
+	eventStream.DisableReconnect = options.DisableEventReconnect
+	eventStream.MaxReconnectAttempts = options.EventMaxReconnectAttempts
+	if options.EventReconnectDelay > 0 {
+		eventStream.ReconnectDelay = options.EventReconnectDelay
+	}
+	if options.EventMaxReconnectDelay > 0 {
+		eventStream.MaxReconnectDelay = options.EventMaxReconnectDelay
+	}
+	transport := newTransport(options)
 	return &Session{
-		User:           User{ID: options.UserID},
-		Status:         DisconnectedStatus,
-		SessionOptions: options,
-		Subscriptions:  map[string]Subscription{},
-		eventStream:    NewEventStream(),
-		Logger:         log,
+		User:                 User{ID: options.UserID},
+		Status:               DisconnectedStatus,
+		SessionOptions:       options,
+		Subscriptions:        map[string]Subscription{},
+		subscriptionPayloads: map[string]interface{}{},
+		watchedUsers:         map[string]int{},
+		eventStream:          eventStream,
+		transport:            transport,
+		streamTransport:      newStreamTransport(transport, options),
+		Logger:               log,
 	}
 }
 
 // GetID tells the ID
 //
 // implements Identifiable
-func (session Session) GetID() string {
+func (session *Session) GetID() string {
+	session.mutex.RLock()
+	defer session.mutex.RUnlock()
 	return session.ID
 }
 
+// GetStatus tells the status of the Session
+func (session *Session) GetStatus() SessionStatus {
+	session.mutex.RLock()
+	defer session.mutex.RUnlock()
+	return session.Status
+}
+
 // IsConnected tells if the Session is connected to a PureConnect server
-func (session Session) IsConnected() bool {
+func (session *Session) IsConnected() bool {
+	session.mutex.RLock()
+	defer session.mutex.RUnlock()
+	return session.isConnected()
+}
+
+func (session *Session) isConnected() bool {
 	return session.Status == ConnectedStatus || session.Status == DisconnectingStatus || session.Status == ChangingStatus
 }
 
 // Events gives the EventSource chan to read for new Server-Sent Events from PureConnect
-func (session Session) Events() chan EventSource {
-	return session.eventStream.Events
+//
+// All calls give the same chan, configured with SessionOptions.EventBufferSize and SessionOptions.EventOverflow.
+// Once the chan is closed (the Session disconnected or ReleaseEvents was called), the next call gives a new chan.
+//
+// Use EventsWithOptions to get a chan per consumer.
+func (session *Session) Events() chan EventSource {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	if session.events == nil || !session.eventStream.Broker.isSubscribed(session.events) {
+		session.events = session.eventStream.Broker.Subscribe(EventsOptions{
+			BufferSize: session.EventBufferSize,
+			Overflow:   session.EventOverflow,
+		})
+	}
+	return session.events
+}
+
+// EventsWithOptions gives a new EventSource chan with the given options
+//
+// Each call gives a new chan that receives all the EventSource.
+// Call ReleaseEvents when the chan is not needed anymore.
+func (session *Session) EventsWithOptions(options EventsOptions) chan EventSource {
+	return session.eventStream.Broker.Subscribe(options)
+}
+
+// ReleaseEvents closes an EventSource chan given by Events
+func (session *Session) ReleaseEvents(events chan EventSource) {
+	session.eventStream.Broker.Unsubscribe(events)
+}
+
+// DroppedEvents tells how many EventSource were dropped for the given EventSource chan
+func (session *Session) DroppedEvents(events chan EventSource) uint64 {
+	return session.eventStream.Broker.Dropped(events)
+}
+
+// TotalDroppedEvents tells how many EventSource were dropped for all EventSource chans
+func (session *Session) TotalDroppedEvents() uint64 {
+	return session.eventStream.Broker.TotalDropped()
 }
 
 // Connect connects to a PureConnect Server
 //
-// If the Session is currently connected, nothing is done
+// If the Session is currently connected, nothing is done.
+// If the Session is currently connecting, Connect waits for that connection and returns its result.
 func (session *Session) Connect() (err error) {
+	return session.ConnectContext(session.Context)
+}
+
+// ConnectContext connects to a PureConnect Server
+//
+// The context cancels the connection requests, including the one to the Server-Sent Events.
+// Once connected, the Session lives with its own Context.
+// Concurrent callers share the same connection, canceling the context of the first caller cancels it for all of them.
+func (session *Session) ConnectContext(context context.Context) (err error) {
 	log := session.Logger.Child(nil, "connect")
-	if session.IsConnected() || session.Status == ConnectingStatus {
-		log.Tracef("Session is already connected or connecting")
+
+	session.mutex.Lock()
+	if session.isConnected() {
+		session.mutex.Unlock()
+		log.Tracef("Session is already connected")
 		return nil
 	}
+	if connecting := session.connecting; connecting != nil {
+		session.mutex.Unlock()
+		log.Tracef("Session is already connecting, waiting")
+		return connecting.waitContext(context)
+	}
+	connecting := newFlight(session.ID)
+	session.connecting = connecting
 	session.Status = ConnectingStatus
+	session.mutex.Unlock()
+
+	err = session.connect(context)
+
+	session.mutex.Lock()
+	session.connecting = nil
+	session.mutex.Unlock()
+	connecting.complete(err)
+	return err
+}
+
+func (session *Session) connect(context context.Context) (err error) {
+	log := session.Logger.Child(nil, "connect")
+
+	session.mutex.RLock()
+	servers := session.Servers
+	session.mutex.RUnlock()
+
 	serverIndex := 0
+	switchovers := 0
 	nextIndex := func(index int, currentServer *url.URL) (int, error) {
-		for index++; index < len(session.Servers); index++ {
-			if currentServer.Host != session.Servers[index].Host {
+		for index++; index < len(servers); index++ {
+			if currentServer.Host != servers[index].Host {
 				return index, nil
 			}
 		}
 		return 0, errors.HTTPServiceUnavailable.WithStack()
 	}
-	for {
+	for len(servers) > 0 {
 		var endpoint *url.URL
+		var response *request.Content
 
-		server := session.Servers[serverIndex]
-		session.APIRoot, _ = server.Parse("/icws")
-		endpoint, err = session.APIRoot.Parse("/connection")
+		server := servers[serverIndex]
+		apiRoot, _ := server.Parse("/icws")
+		session.mutex.Lock()
+		session.APIRoot = apiRoot
+		session.mutex.Unlock()
+		endpoint, err = apiRoot.Parse("/connection")
 		if err != nil {
 			log.Errorf("Failed to create endpoint: %s/connection", server.String())
 			serverIndex, err = nextIndex(serverIndex, server)
@@ -151,7 +435,7 @@ func (session *Session) Connect() (err error) {
 			Version              VersionInfo      `json:"version"`
 		}{}
 
-		err = session.sendPost("/connection?include=features,default-workstation,version",
+		response, err = session.sendRequest(context, http.MethodPost, "/connection?include=features,default-workstation,version", nil, nil,
 			struct {
 				Type        string `json:"__type"`
 				Application string `json:"applicationName"`
@@ -166,8 +450,23 @@ func (session *Session) Connect() (err error) {
 			&results,
 		)
 		if errors.Is(err, errors.HTTPServiceUnavailable) {
-			// TODO: On HTTP 503, we receive a list of alternate hosts that we should connect to
-			// We also need to reset the serverIndex after getting the new list
+			// On HTTP 503, the server is switching over and sends the list of alternate hosts we should connect to
+			alternates := alternateServers(server, getAlternateHosts(response))
+			if len(alternates) > 0 && switchovers < session.MaxSwitchoverAttempts {
+				switchovers++
+				log.Warnf("Server %s is switching over, trying alternates %v (attempt %d/%d)", server.Host, alternates, switchovers, session.MaxSwitchoverAttempts)
+				servers = alternates
+				session.mutex.Lock()
+				session.Servers = servers
+				session.mutex.Unlock()
+				serverIndex = 0
+				continue
+			}
+			if len(alternates) > 0 {
+				log.Errorf("Too many switchovers (%d), giving up", switchovers)
+				err = errors.HTTPServiceUnavailable.WithStack()
+				break
+			}
 			serverIndex, err = nextIndex(serverIndex, server)
 			if err != nil {
 				break // We should return an error to the caller now...
@@ -175,26 +474,20 @@ func (session *Session) Connect() (err error) {
 			continue
 		} else if err != nil {
 			log.Errorf("Failed to connect to %s", endpoint, err)
+			if context != nil && context.Err() != nil {
+				break // The caller gave up, there is no point trying the other servers
+			}
 			serverIndex, err = nextIndex(serverIndex, server)
 			if err != nil {
 				break // We should return an error to the caller now...
 			}
 			continue
 		}
+		session.mutex.Lock()
 		session.ID = results.SessionID
 		session.Token = results.Token
-		if session.TokenUpdated != nil {
-			log.Tracef("Sending new Token to chan")
-			session.TokenUpdated <- UpdatedToken{
-				Token:   session.Token,
-				Context: session.Context,
-			}
-		}
-		if len(results.Alternates) > 0 {
-			session.Servers = make([]*url.URL, len(results.Alternates))
-			for i := 0; i < len(results.Alternates); i++ {
-				session.Servers[i] = core.Must(url.Parse(fmt.Sprintf("%s://%s:%s", server.Scheme, results.Alternates[i], server.Port())))
-			}
+		if alternates := alternateServers(server, results.Alternates); len(alternates) > 0 {
+			session.Servers = alternates
 		}
 		if results.DefaultWorkstationID != nil && len(*results.DefaultWorkstationID) > 0 {
 			session.DefaultWorkstationID = *results.DefaultWorkstationID
@@ -203,22 +496,59 @@ func (session *Session) Connect() (err error) {
 		session.User.DisplayName = results.DisplayName
 		session.Status = ConnectedStatus
 		session.Features = results.Features
-		session.Logger = session.Logger.Record("session", session.ID)
+		session.mutex.Unlock()
+		log = log.Record("session", results.SessionID)
 
-		err = session.startMessageProcessing()
+		if session.TokenUpdated != nil {
+			log.Tracef("Sending new Token to chan")
+			session.TokenUpdated <- UpdatedToken{
+				Token:   results.Token,
+				Context: session.Context,
+			}
+		}
+
+		session.startDispatching()
+		err = session.startMessageProcessing(context)
 		if err != nil {
-			return err
+			return session.abortConnect(err)
 		}
+		session.startKeepAlive()
 
-		err = session.Subscribe(UserStatusMessage{}, UserStatusSubscription{
-			UserIDs: IDList(session.User),
-		})
+		err = session.subscribeUserStatuses(withoutRecovery(context))
 		if err != nil {
-			return err
+			return session.abortConnect(err)
 		}
 		return nil
 	}
+	if len(servers) == 0 {
+		err = errors.ArgumentMissing.With("servers")
+	}
+	session.mutex.Lock()
+	session.Status = DisconnectedStatus
+	session.mutex.Unlock()
+	return err
+}
+
+// abortConnect stops what connect started after the Session logged in
+//
+// The PureConnect session is deleted if possible and the Session is disconnected.
+// returns the given error
+func (session *Session) abortConnect(err error) error {
+	log := session.Logger.Child(nil, "connect")
+
+	log.Errorf("Failed to complete the connection, disconnecting", err)
+	session.stopKeepAlive()
+	session.eventStream.stop()
+	session.stopDispatching()
+	if _, deleteErr := session.sendRequest(session.Context, http.MethodDelete, "/connection", nil, nil, nil, nil); deleteErr != nil {
+		log.Warnf("Failed to delete the PureConnect session: %s", deleteErr.Error())
+	}
+	session.mutex.Lock()
 	session.Status = DisconnectedStatus
+	session.ID = ""
+	session.Token = ""
+	session.Cookies = nil
+	session.mutex.Unlock()
 	return err
 }
 
@@ -227,46 +557,81 @@ func (session *Session) Connect() (err error) {
 // All subscriptions are canceled prior to the disconnection.
 // Also disconnected the Station, if any.
 func (session *Session) Disconnect() error {
+	return session.DisconnectContext(session.Context)
+}
+
+// DisconnectContext disconnects the Session from PureConnect
+//
+// The context cancels the requests that unsubscribe and disconnect the Session.
+// If the context is done while unsubscribing, the Session stays connected and can be disconnected again later.
+// If the context is done while disconnecting, the Session is disconnected but PureConnect might not know it yet.
+func (session *Session) DisconnectContext(context context.Context) error {
 	log := session.Logger.Child(nil, "disconnect")
-	if !session.IsConnected() || session.Status == DisconnectingStatus {
+
+	session.mutex.Lock()
+	if !session.isConnected() || session.Status == DisconnectingStatus {
+		session.mutex.Unlock()
 		return nil
 	}
-	var errs errors.MultiError
 	session.Status = DisconnectingStatus
+	subscriptions := make(map[string]Subscription, len(session.Subscriptions))
 	for key, subscription := range session.Subscriptions {
-		if err := subscription.Unsubscribe(session); err != nil {
+		subscriptions[key] = subscription
+	}
+	stationSettings := session.StationSettings
+	session.mutex.Unlock()
+
+	var errs errors.MultiError
+	session.stopKeepAlive()
+	for key, subscription := range subscriptions {
+		if err := subscription.Unsubscribe(context, session); err != nil {
 			errs.Append(err)
 		} else {
 			log.Debugf("Unsubcribed from %s", subscription.GetType())
+			session.mutex.Lock()
 			delete(session.Subscriptions, key)
+			delete(session.subscriptionPayloads, key)
+			session.mutex.Unlock()
 		}
 	}
-	if session.StationSettings != nil {
-		if err := session.StationSettings.Disconnect(session); err != nil {
+	if stationSettings != nil {
+		if err := stationSettings.Disconnect(context, session); err != nil {
 			errs.Append(err)
 		} else {
-			log.Debugf("Disconnected from station %s", session.StationSettings)
+			log.Debugf("Disconnected from station %s", stationSettings)
+			session.mutex.Lock()
 			session.StationSettings = nil
+			session.mutex.Unlock()
 		}
 	}
 	if !errs.IsEmpty() {
+		if context != nil && context.Err() != nil {
+			session.mutex.Lock()
+			session.Status = ConnectedStatus
+			session.mutex.Unlock()
+			session.startKeepAlive()
+		}
 		return errs.AsError()
 	}
 
 	session.stopMessageProcessing()
 	log.Debugf("Message Processing stopped")
 
-	errs.Append(session.sendDelete("/connection"))
-	if !errs.IsEmpty() {
+	errs.Append(session.sendDeleteContext(context, "/connection"))
+	if errs.IsEmpty() || (context != nil && context.Err() != nil) {
+		session.mutex.Lock()
 		log.Debugf("Disconnected from %s", session.APIRoot.Host)
 		session.Status = DisconnectedStatus
 		session.ID = ""
+		session.mutex.Unlock()
 	}
 	return errs.AsError()
 }
 
 // HasSupport tells if the Session supports the given PureConnect feature
-func (session Session) HasSupport(featureName string) bool {
+func (session *Session) HasSupport(featureName string) bool {
+	session.mutex.RLock()
+	defer session.mutex.RUnlock()
 	featureName = strings.ToLower(featureName)
 	for _, feature := range session.Features {
 		if feature.Name == featureName {
@@ -277,7 +642,9 @@ func (session Session) HasSupport(featureName string) bool {
 }
 
 // HasSupport tells if the Session supports the given PureConnect feature
-func (session Session) HasSupportWithAtLeastVersion(featureName string, minimumVersion int) bool {
+func (session *Session) HasSupportWithAtLeastVersion(featureName string, minimumVersion int) bool {
+	session.mutex.RLock()
+	defer session.mutex.RUnlock()
 	featureName = strings.ToLower(featureName)
 	for _, feature := range session.Features {
 		if feature.Name == featureName {
@@ -290,7 +657,9 @@ func (session Session) HasSupportWithAtLeastVersion(featureName string, minimumV
 // String gets a text representation
 //
 // implements fmt.Stringer
-func (session Session) String() string {
+func (session *Session) String() string {
+	session.mutex.RLock()
+	defer session.mutex.RUnlock()
 	builder := strings.Builder{}
 	builder.WriteString("Session ")
 	if len(session.ID) > 0 {
@@ -313,33 +682,89 @@ func (session Session) String() string {
 // MarshalJSON marshals into JSON
 //
 // implements json.Marshaler
-func (session Session) MarshalJSON() ([]byte, error) {
-	type surrogate Session
+func (session *Session) MarshalJSON() ([]byte, error) {
+	session.mutex.RLock()
+	defer session.mutex.RUnlock()
 	servers := make([]*core.URL, len(session.Servers))
 	for i := 0; i < len(session.Servers); i++ {
 		servers[i] = (*core.URL)(session.Servers[i])
 	}
 	data, err := json.Marshal(struct {
-		surrogate
+		ID                   string           `json:"id"`
+		Token                string           `json:"token"`
+		Cookies              []*http.Cookie   `json:"cookies"`
+		Timezone             string           `json:"timezone"`
+		Version              VersionInfo      `json:"pureconnectVersion"`
+		User                 User             `json:"user"`
+		DefaultWorkstationID string           `json:"defaultWorkstationId"`
+		StationSettings      StationSettings  `json:"stationSettings"`
+		Status               SessionStatus    `json:"status"`
+		Features             []SessionFeature `json:"features"`
+		SessionOptions
 		APIRoot *core.URL
 		Servers []*core.URL
 	}{
-		surrogate: surrogate(session),
-		APIRoot:   (*core.URL)(session.APIRoot),
-		Servers:   servers,
+		ID:                   session.ID,
+		Token:                session.Token,
+		Cookies:              session.Cookies,
+		Timezone:             session.Timezone,
+		Version:              session.Version,
+		User:                 session.User,
+		DefaultWorkstationID: session.DefaultWorkstationID,
+		StationSettings:      session.StationSettings,
+		Status:               session.Status,
+		Features:             session.Features,
+		SessionOptions:       session.SessionOptions,
+		APIRoot:              (*core.URL)(session.APIRoot),
+		Servers:              servers,
 	})
 	return data, errors.JSONMarshalError.Wrap(err)
 }
 
-func (session *Session) startMessageProcessing() error {
-	if session.HasSupportWithAtLeastVersion("messaging", 2) { // Server-Sent Events are supported
-		return session.eventStream.Connect(session, "/messaging/messages")
+// getCredentials gets the credentials to send with requests to PureConnect
+func (session *Session) getCredentials() (token string, cookies []*http.Cookie) {
+	session.mutex.RLock()
+	defer session.mutex.RUnlock()
+	return session.Token, session.Cookies
+}
+
+// getAlternateHosts gets the alternate host list from an HTTP 503 response
+func getAlternateHosts(response *request.Content) []string {
+	if response == nil || len(response.Data) == 0 {
+		return []string{}
 	}
-	return errors.NotImplemented.WithStack()
+	results := struct {
+		Alternates []string `json:"alternateHostList"`
+	}{}
+	if err := json.Unmarshal(response.Data, &results); err != nil {
+		return []string{}
+	}
+	return results.Alternates
 }
 
-func (session *Session) stopMessageProcessing() {
-	if session.HasSupportWithAtLeastVersion("messaging", 2) { // Server-Sent Events are supported
-		session.eventStream.Disconnect()
+// alternateServers builds the server list from alternate hosts, using the scheme and port of the current server
+//
+// Hosts that do not make a valid URL are skipped.
+func alternateServers(server *url.URL, alternates []string) []*url.URL {
+	servers := make([]*url.URL, 0, len(alternates))
+	for _, alternate := range alternates {
+		alternateURL, err := url.Parse(fmt.Sprintf("%s://%s:%s", server.Scheme, alternate, server.Port()))
+		if err != nil || len(alternateURL.Hostname()) == 0 {
+			continue
+		}
+		servers = append(servers, alternateURL)
+	}
+	return servers
+}
+
+func (session *Session) startMessageProcessing(context context.Context) error {
+	if !session.ForceMessagePolling && session.HasSupportWithAtLeastVersion("messaging", 2) { // Server-Sent Events are supported
+		return session.eventStream.ConnectContext(context, session, "/messaging/messages")
 	}
+	return session.eventStream.Poll(session, "/messaging/messages", session.MessagePollingInterval)
+}
+
+func (session *Session) stopMessageProcessing() {
+	session.eventStream.Disconnect()
+	session.stopDispatching()
 }
@@ -0,0 +1,49 @@
+package icws
+
+import (
+	"fmt"
+	"time"
+)
+
+// SessionExpiredMessage is sent by the Session when its PureConnect session expired
+//
+// If the Session is configured to reconnect, a SessionReconnectedMessage follows once reconnected
+type SessionExpiredMessage struct {
+	SessionID    string    `json:"sessionId"`
+	ExpiredAt    time.Time `json:"expiredAt"`
+	Reconnecting bool      `json:"reconnecting"`
+}
+
+// SessionReconnectedMessage is sent by the Session when it reconnected after its PureConnect session expired
+type SessionReconnectedMessage struct {
+	SessionID     string    `json:"sessionId"`
+	ReconnectedAt time.Time `json:"reconnectedAt"`
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (message SessionExpiredMessage) GetType() string {
+	return "urn:gildas:icws:sessionExpiredMessage"
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (message SessionExpiredMessage) String() string {
+	return fmt.Sprintf("Session %s expired at %s", message.SessionID, message.ExpiredAt.Format(time.RFC3339))
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (message SessionReconnectedMessage) GetType() string {
+	return "urn:gildas:icws:sessionReconnectedMessage"
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (message SessionReconnectedMessage) String() string {
+	return fmt.Sprintf("Session %s reconnected at %s", message.SessionID, message.ReconnectedAt.Format(time.RFC3339))
+}
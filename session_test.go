@@ -0,0 +1,931 @@
+package icws_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-icws"
+	"github.com/gildas/go-logger"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+)
+
+type SessionSuite struct {
+	suite.Suite
+	Name   string
+	Logger *logger.Logger
+	Start  time.Time
+}
+
+func TestSessionSuite(t *testing.T) {
+	suite.Run(t, new(SessionSuite))
+}
+
+// *****************************************************************************
+// Suite Tools
+
+func (suite *SessionSuite) SetupSuite() {
+	_ = godotenv.Load()
+	suite.Name = strings.TrimSuffix(reflect.TypeOf(suite).Elem().Name(), "Suite")
+	suite.Logger = logger.Create("test",
+		&logger.FileStream{
+			Path:         fmt.Sprintf("./log/test-%s.log", strings.ToLower(suite.Name)),
+			Unbuffered:   true,
+			SourceInfo:   true,
+			FilterLevels: logger.NewLevelSet(logger.TRACE),
+		},
+	).Child("test", "test")
+	suite.Logger.Infof("Suite Start: %s %s", suite.Name, strings.Repeat("=", 80-14-len(suite.Name)))
+}
+
+func (suite *SessionSuite) TearDownSuite() {
+	suite.Logger.Debugf("Tearing down")
+	if suite.T().Failed() {
+		suite.Logger.Warnf("At least one test failed, we are not cleaning")
+		suite.T().Log("At least one test failed, we are not cleaning")
+	} else {
+		suite.Logger.Infof("All tests succeeded, we are cleaning")
+	}
+	suite.Logger.Infof("Suite End: %s %s", suite.Name, strings.Repeat("=", 80-12-len(suite.Name)))
+	suite.Logger.Close()
+}
+
+func (suite *SessionSuite) BeforeTest(suiteName, testName string) {
+	suite.Logger.Infof("Test Start: %s %s", testName, strings.Repeat("-", 80-13-len(testName)))
+	suite.Start = time.Now()
+}
+
+func (suite *SessionSuite) AfterTest(suiteName, testName string) {
+	duration := time.Since(suite.Start)
+	if suite.T().Failed() {
+		suite.Logger.Errorf("Test %s failed", testName)
+	}
+	suite.Logger.Record("duration", duration.String()).Infof("Test End: %s %s", testName, strings.Repeat("-", 80-11-len(testName)))
+}
+
+// NewConnectedSession starts a FakeServer and connects a new Session to it
+//
+// The configure funcs can prepare the server and the options before the Session connects.
+// The Session is disconnected and the server closed when the test ends.
+func (suite *SessionSuite) NewConnectedSession(configure ...func(server *FakeServer, options *icws.SessionOptions)) (*icws.Session, *FakeServer) {
+	server := NewFakeServer()
+	suite.T().Cleanup(server.Close)
+
+	options := icws.SessionOptions{
+		Servers:     []*url.URL{server.URL("127.0.0.1")},
+		UserID:      "agent",
+		Password:    "s3cr3t",
+		Application: "test",
+	}
+	for _, configurator := range configure {
+		configurator(server, &options)
+	}
+	session := icws.NewSession(options)
+	suite.Require().Nil(session.Connect())
+	suite.T().Cleanup(func() { _ = session.Disconnect() })
+	return session, server
+}
+
+// FakeServer is a minimal PureConnect Server for tests
+type FakeServer struct {
+	*httptest.Server
+	SessionID string
+	Logins    int
+	Requests  map[string]int
+	// Unavailable tells which host (host:port) answers with HTTP 503 and which alternate hosts it sends back
+	Unavailable map[string][]string
+	// StreamEvents receives the raw Server-Sent Events to send, an empty string closes the stream
+	StreamEvents chan string
+	LastEventID  string
+	// Messages are sent to the next poll of the messages
+	Messages []string
+	// Expired tells if the PureConnect session expired, a new login resets it
+	Expired bool
+	// Responses contains the JSON bodies to answer per "METHOD path"
+	Responses map[string]string
+	// Payloads records the last body received per "METHOD path"
+	Payloads map[string]string
+	// Handlers handle the requests per "METHOD path"
+	Handlers map[string]http.HandlerFunc
+	mutex    sync.Mutex
+}
+
+func NewFakeServer() *FakeServer {
+	server := newFakeServer()
+	server.Server = httptest.NewServer(http.HandlerFunc(server.handle))
+	return server
+}
+
+func NewFakeTLSServer() *FakeServer {
+	server := newFakeServer()
+	server.Server = httptest.NewTLSServer(http.HandlerFunc(server.handle))
+	return server
+}
+
+func newFakeServer() *FakeServer {
+	return &FakeServer{
+		SessionID:    "1234",
+		Requests:     map[string]int{},
+		Unavailable:  map[string][]string{},
+		StreamEvents: make(chan string),
+		Responses:    map[string]string{},
+		Payloads:     map[string]string{},
+		Handlers:     map[string]http.HandlerFunc{},
+	}
+}
+
+func (server *FakeServer) URL(host string) *url.URL {
+	serverURL, _ := url.Parse(server.Server.URL)
+	_, port, _ := net.SplitHostPort(serverURL.Host)
+	serverURL.Host = net.JoinHostPort(host, port)
+	return serverURL
+}
+
+func (server *FakeServer) LoginCount() int {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.Logins
+}
+
+func (server *FakeServer) Count(method, path string) int {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.Requests[method+" "+path]
+}
+
+func (server *FakeServer) Payload(method, path string) string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.Payloads[method+" "+path]
+}
+
+func (server *FakeServer) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	server.mutex.Lock()
+	server.Requests[r.Method+" "+r.URL.Path]++
+	if len(body) > 0 {
+		server.Payloads[r.Method+" "+r.URL.Path] = string(body)
+	}
+	alternates, unavailable := server.Unavailable[r.Host]
+	expired := server.Expired
+	response, found := server.Responses[r.Method+" "+r.URL.Path]
+	handler, handled := server.Handlers[r.Method+" "+r.URL.Path]
+	server.mutex.Unlock()
+
+	if unavailable {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(struct {
+			ErrorID    string   `json:"errorId"`
+			Alternates []string `json:"alternateHostList"`
+		}{
+			ErrorID:    "error.server.unavailable",
+			Alternates: alternates,
+		})
+		return
+	}
+
+	sessionPath := "/icws/" + server.SessionID
+	switch {
+	case handled:
+		handler(w, r)
+	case r.Method == http.MethodPost && r.URL.Path == "/icws/connection":
+		server.mutex.Lock()
+		server.Logins++
+		server.Expired = false
+		server.mutex.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		http.SetCookie(w, &http.Cookie{Name: "icws_" + server.SessionID, Value: "cookie"})
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(fmt.Sprintf(`{
+			"csrfToken": "token",
+			"sessionId": "%s",
+			"icServer": "fake",
+			"userID": "agent",
+			"userDisplayName": "Agent",
+			"features": [{"featureId": "messaging", "version": 2}],
+			"version": {"majorVersion": 20, "minorVersion": 1, "su": 0, "build": 1}
+		}`, server.SessionID)))
+	case expired && strings.HasPrefix(r.URL.Path, sessionPath+"/"):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"errorId": "error.request.connection.authenticationFailure", "message": "The session is not valid"}`))
+	case r.Method == http.MethodGet && r.URL.Path == sessionPath+"/messaging/messages" && r.Header.Get("Accept") != "text/event-stream":
+		server.mutex.Lock()
+		messages := server.Messages
+		server.Messages = []string{}
+		server.mutex.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("[" + strings.Join(messages, ",") + "]"))
+	case r.Method == http.MethodGet && r.URL.Path == sessionPath+"/messaging/messages":
+		server.mutex.Lock()
+		server.LastEventID = r.Header.Get("Last-Event-ID")
+		server.mutex.Unlock()
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		flusher, _ := w.(http.Flusher)
+		for {
+			if flusher != nil {
+				flusher.Flush()
+			}
+			select {
+			case chunk := <-server.StreamEvents:
+				if len(chunk) == 0 {
+					return
+				}
+				_, _ = w.Write([]byte(chunk))
+			case <-r.Context().Done():
+				return
+			}
+		}
+	case r.Method == http.MethodGet && r.URL.Path == sessionPath+"/connection/version":
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"majorVersion": 20, "minorVersion": 1, "su": 0, "build": 1, "productId": "CIC"}`))
+	case found:
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	case strings.HasPrefix(r.URL.Path, sessionPath+"/"):
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// *****************************************************************************
+
+func (suite *SessionSuite) TestCanConnect() {
+	server := NewFakeServer()
+	defer server.Close()
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:     []*url.URL{server.URL("127.0.0.1")},
+		UserID:      "agent",
+		Password:    "s3cr3t",
+		Application: "test",
+	})
+	err := session.Connect()
+	suite.Require().Nil(err)
+	suite.Assert().True(session.IsConnected())
+	suite.Assert().Equal(server.SessionID, session.ID)
+	suite.Assert().Equal("token", session.Token)
+	suite.Assert().Equal(1, server.LoginCount())
+	suite.Require().Nil(session.Disconnect())
+}
+
+func (suite *SessionSuite) TestShouldDisconnectWhenConnectCannotComplete() {
+	server := NewFakeServer()
+	defer server.Close()
+	server.Handlers["PUT /icws/1234/messaging/subscriptions/status/user-statuses"] = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:           []*url.URL{server.URL("127.0.0.1")},
+		UserID:            "agent",
+		Password:          "s3cr3t",
+		Application:       "test",
+		KeepAliveInterval: time.Hour,
+		RequestAttempts:   1,
+	})
+	session.OnUserStatus(func(icws.UserStatusMessage) {})
+	err := session.Connect()
+	suite.Require().NotNil(err, "Connect should fail when the user statuses cannot be subscribed")
+	suite.Assert().False(session.IsConnected())
+	suite.Assert().Equal(icws.DisconnectedStatus, session.GetStatus())
+	suite.Assert().Empty(session.GetID())
+	suite.Assert().Equal(1, server.Count(http.MethodDelete, "/icws/1234/connection"), "The PureConnect session should be deleted")
+
+	server.mutex.Lock()
+	delete(server.Handlers, "PUT /icws/1234/messaging/subscriptions/status/user-statuses")
+	server.mutex.Unlock()
+	suite.Require().Nil(session.Connect(), "The Session should be able to connect again")
+	suite.Assert().Equal(2, server.LoginCount())
+	suite.Require().Nil(session.Disconnect())
+}
+
+func (suite *SessionSuite) TestCanConnectWithSwitchover() {
+	server := NewFakeServer()
+	defer server.Close()
+	server.Unavailable[server.URL("127.0.0.1").Host] = []string{"localhost"}
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:     []*url.URL{server.URL("127.0.0.1")},
+		UserID:      "agent",
+		Password:    "s3cr3t",
+		Application: "test",
+	})
+	err := session.Connect()
+	suite.Require().Nil(err)
+	suite.Assert().True(session.IsConnected())
+	suite.Require().NotNil(session.APIRoot)
+	suite.Assert().Equal("localhost", session.APIRoot.Hostname())
+	suite.Require().Len(session.Servers, 1)
+	suite.Assert().Equal("localhost", session.Servers[0].Hostname())
+	suite.Require().Nil(session.Disconnect())
+}
+
+func (suite *SessionSuite) TestShouldSkipMalformedAlternateHosts() {
+	server := NewFakeServer()
+	defer server.Close()
+	server.Unavailable[server.URL("127.0.0.1").Host] = []string{"bad host%", "", "localhost"}
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:     []*url.URL{server.URL("127.0.0.1")},
+		UserID:      "agent",
+		Password:    "s3cr3t",
+		Application: "test",
+	})
+	suite.Require().Nil(session.Connect())
+	defer session.Disconnect()
+	suite.Assert().Equal("localhost", session.APIRoot.Hostname())
+	suite.Require().Len(session.Servers, 1)
+	suite.Assert().Equal("localhost", session.Servers[0].Hostname())
+}
+
+func (suite *SessionSuite) TestShouldFailConnectingWithSwitchoverLoop() {
+	server := NewFakeServer()
+	defer server.Close()
+	server.Unavailable[server.URL("127.0.0.1").Host] = []string{"localhost"}
+	server.Unavailable[server.URL("localhost").Host] = []string{"127.0.0.1"}
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:               []*url.URL{server.URL("127.0.0.1")},
+		UserID:                "agent",
+		Password:              "s3cr3t",
+		Application:           "test",
+		MaxSwitchoverAttempts: 3,
+	})
+	err := session.Connect()
+	suite.Require().NotNil(err)
+	suite.Assert().Truef(errors.Is(err, errors.HTTPServiceUnavailable), "Error should be an HTTPServiceUnavailable")
+	suite.Assert().False(session.IsConnected())
+	suite.Assert().Equal(4, server.Count(http.MethodPost, "/icws/connection"))
+}
+
+func (suite *SessionSuite) TestCanRecoverFromSwitchover() {
+	server := NewFakeServer()
+	defer server.Close()
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:            []*url.URL{server.URL("127.0.0.1")},
+		UserID:             "agent",
+		Password:           "s3cr3t",
+		Application:        "test",
+		SwitchoverRecovery: true,
+	})
+	err := session.Connect()
+	suite.Require().Nil(err)
+	events := session.Events()
+
+	server.Unavailable[server.URL("127.0.0.1").Host] = []string{"localhost"}
+	version, err := session.GetVersion()
+	suite.Require().Nil(err, "Failed to recover from switchover")
+	suite.Assert().Equal(20, version.Major)
+	suite.Assert().Equal("localhost", session.APIRoot.Hostname())
+	suite.Assert().Equal(2, server.LoginCount())
+	// 1 for the first Connect, 1 for the Connect after the switchover which restores the user statuses
+	suite.Assert().Equal(2, server.Count(http.MethodPut, "/icws/1234/messaging/subscriptions/status/user-statuses"))
+
+	server.StreamEvents <- "data: {\"__type\": \"urn:inin.com:status:userStatusMessage\", \"isDelta\": true, \"userStatusList\": []}\n\n"
+	select {
+	case event := <-events:
+		suite.Assert().IsType(&icws.UserStatusMessage{}, event.Message, "The Events chan should still receive messages")
+	case <-time.After(5 * time.Second):
+		suite.FailNow("Timeout while waiting for an event")
+	}
+	suite.Require().Nil(session.Disconnect())
+}
+
+func (suite *SessionSuite) TestShouldFailRecoveringFromSwitchoverWithoutAlternates() {
+	server := NewFakeServer()
+	defer server.Close()
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:            []*url.URL{server.URL("127.0.0.1")},
+		UserID:             "agent",
+		Password:           "s3cr3t",
+		Application:        "test",
+		SwitchoverRecovery: true,
+	})
+	err := session.Connect()
+	suite.Require().Nil(err)
+
+	server.Unavailable[server.URL("127.0.0.1").Host] = []string{}
+	_, err = session.GetVersion()
+	suite.Require().NotNil(err)
+	suite.Assert().Truef(errors.Is(err, icws.SwitchoverFailed), "Error should be a SwitchoverFailed, got %s", err)
+}
+
+func (suite *SessionSuite) TestShouldReplayOnlyOnceAfterSwitchover() {
+	server := NewFakeServer()
+	defer server.Close()
+	server.Handlers["GET /icws/1234/configuration/users"] = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"errorId": "error.server.unavailable", "alternateHostList": ["localhost"]}`))
+	}
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:            []*url.URL{server.URL("127.0.0.1")},
+		UserID:             "agent",
+		Password:           "s3cr3t",
+		Application:        "test",
+		SwitchoverRecovery: true,
+		RequestAttempts:    1,
+	})
+	suite.Require().Nil(session.Connect())
+	defer session.Disconnect()
+
+	_, err := session.GetUsers()
+	suite.Require().NotNil(err)
+	suite.Assert().Truef(errors.Is(err, icws.SwitchoverFailed), "Error should be a SwitchoverFailed, got %s", err)
+	suite.Assert().Equal(2, server.LoginCount(), "The Session should recover only once")
+	suite.Assert().Equal(2, server.Count(http.MethodGet, "/icws/1234/configuration/users"), "The request should be replayed only once")
+}
+
+func (suite *SessionSuite) TestShouldNotRecoverFromPlainServiceUnavailable() {
+	server := NewFakeServer()
+	defer server.Close()
+	server.Handlers["GET /icws/1234/configuration/users"] = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:            []*url.URL{server.URL("127.0.0.1")},
+		UserID:             "agent",
+		Password:           "s3cr3t",
+		Application:        "test",
+		SwitchoverRecovery: true,
+		RequestAttempts:    1,
+	})
+	suite.Require().Nil(session.Connect())
+	defer session.Disconnect()
+
+	_, err := session.GetUsers()
+	suite.Require().NotNil(err)
+	suite.Assert().True(errors.Is(err, errors.HTTPServiceUnavailable))
+	suite.Assert().False(errors.Is(err, icws.SwitchoverFailed), "The connection is fine, there is nothing to recover")
+	suite.Assert().Equal(1, server.LoginCount())
+	suite.Assert().Equal(1, server.Count(http.MethodGet, "/icws/1234/connection"), "The connection should be checked")
+}
+
+func (suite *SessionSuite) TestShouldKeepSubscriptionsNotRestoredAfterSwitchover() {
+	server := NewFakeServer()
+	defer server.Close()
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:            []*url.URL{server.URL("127.0.0.1")},
+		UserID:             "agent",
+		Password:           "s3cr3t",
+		Application:        "test",
+		SwitchoverRecovery: true,
+		RequestAttempts:    1,
+	})
+	suite.Require().Nil(session.Connect())
+	defer session.Disconnect()
+	suite.Require().Nil(session.Subscribe(icws.LicenseMessage{}, icws.LicenseSubscription{Licenses: []string{"I3_ACCESS_CLIENT"}}))
+	suite.Require().Nil(session.Subscribe(icws.StatusMessageMessage{}, struct{}{}))
+
+	server.mutex.Lock()
+	server.Handlers["PUT /icws/1234/messaging/subscriptions/licenses"] = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	server.Unavailable[server.URL("127.0.0.1").Host] = []string{"localhost"}
+	server.mutex.Unlock()
+
+	_, err := session.GetVersion()
+	suite.Require().NotNil(err, "The license subscription could not be restored")
+	suite.Assert().Equal("localhost", session.APIRoot.Hostname())
+	_, found := session.GetSubscription(icws.LicenseMessage{}.GetType())
+	suite.Assert().True(found, "The license subscription should be kept for the next recovery")
+	suite.Assert().Equal(2, server.Count(http.MethodPut, "/icws/1234/messaging/subscriptions/status/status-messages"), "The other subscriptions should be restored")
+	suite.Assert().Equal(2, server.Count(http.MethodPut, "/icws/1234/messaging/subscriptions/status/user-statuses"))
+}
+
+func (suite *SessionSuite) TestCanReconnectEventStream() {
+	server := NewFakeServer()
+	defer server.Close()
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:             []*url.URL{server.URL("127.0.0.1")},
+		UserID:              "agent",
+		Password:            "s3cr3t",
+		Application:         "test",
+		EventReconnectDelay: 10 * time.Millisecond,
+	})
+	events := session.Events()
+	err := session.Connect()
+	suite.Require().Nil(err)
+	defer session.Disconnect()
+
+	nextEvent := func() icws.EventSource {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			suite.FailNow("Timeout while waiting for an event")
+			return icws.EventSource{}
+		}
+	}
+
+	server.StreamEvents <- "retry: 20\nid: 1\ndata: {\"__type\": \"urn:inin.com:status:userStatusMessage\", \"isDelta\": true, \"userStatusList\": []}\n\n"
+	event := nextEvent()
+	suite.Assert().Equal("1", event.ID)
+	suite.Assert().IsType(&icws.UserStatusMessage{}, event.Message)
+
+	server.StreamEvents <- "" // Closes the stream
+	event = nextEvent()
+	disconnected, ok := event.Message.(icws.EventStreamDisconnectedMessage)
+	suite.Require().Truef(ok, "Message should be an EventStreamDisconnectedMessage, got %T", event.Message)
+	suite.Assert().Equal("1", disconnected.LastEventID)
+
+	event = nextEvent()
+	reconnected, ok := event.Message.(icws.EventStreamReconnectedMessage)
+	suite.Require().Truef(ok, "Message should be an EventStreamReconnectedMessage, got %T", event.Message)
+	suite.Assert().Equal(1, reconnected.Attempts)
+	suite.Assert().Equal(2, server.Count(http.MethodGet, "/icws/1234/messaging/messages"))
+	server.mutex.Lock()
+	suite.Assert().Equal("1", server.LastEventID)
+	server.mutex.Unlock()
+}
+
+func (suite *SessionSuite) TestCanPollMessages() {
+	server := NewFakeServer()
+	defer server.Close()
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:                []*url.URL{server.URL("127.0.0.1")},
+		UserID:                 "agent",
+		Password:               "s3cr3t",
+		Application:            "test",
+		ForceMessagePolling:    true,
+		MessagePollingInterval: 10 * time.Millisecond,
+	})
+	events := session.Events()
+	err := session.Connect()
+	suite.Require().Nil(err)
+
+	server.mutex.Lock()
+	server.Messages = []string{
+		`{"__type": "urn:inin.com:status:userStatusMessage", "isDelta": true, "userStatusList": []}`,
+		`{"__type": "urn:inin.com:status:licenseMessage", "isDelta": true, "licenseAssignedStatusList": []}`,
+	}
+	server.mutex.Unlock()
+
+	for _, expected := range []icws.Message{&icws.UserStatusMessage{}, &icws.LicenseMessage{}} {
+		select {
+		case event := <-events:
+			suite.Assert().IsType(expected, event.Message)
+		case <-time.After(5 * time.Second):
+			suite.FailNow("Timeout while waiting for an event")
+		}
+	}
+	suite.Require().Nil(session.Disconnect())
+}
+
+func (suite *SessionSuite) TestCanDetectSessionExpiration() {
+	server := NewFakeServer()
+	defer server.Close()
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:           []*url.URL{server.URL("127.0.0.1")},
+		UserID:            "agent",
+		Password:          "s3cr3t",
+		Application:       "test",
+		KeepAliveInterval: 10 * time.Millisecond,
+	})
+	events := session.Events()
+	err := session.Connect()
+	suite.Require().Nil(err)
+
+	server.mutex.Lock()
+	server.Expired = true
+	server.mutex.Unlock()
+
+	select {
+	case event := <-events:
+		expired, ok := event.Message.(icws.SessionExpiredMessage)
+		suite.Require().Truef(ok, "Message should be a SessionExpiredMessage, got %T", event.Message)
+		suite.Assert().Equal(server.SessionID, expired.SessionID)
+		suite.Assert().False(expired.Reconnecting)
+	case <-time.After(5 * time.Second):
+		suite.FailNow("Timeout while waiting for the session to expire")
+	}
+	suite.Assert().False(session.IsConnected())
+	suite.Assert().Equal(1, server.LoginCount())
+}
+
+func (suite *SessionSuite) TestCanReconnectWhenSessionExpired() {
+	server := NewFakeServer()
+	defer server.Close()
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:            []*url.URL{server.URL("127.0.0.1")},
+		UserID:             "agent",
+		Password:           "s3cr3t",
+		Application:        "test",
+		KeepAliveInterval:  10 * time.Millisecond,
+		KeepAliveReconnect: true,
+	})
+	events := session.Events()
+	err := session.Connect()
+	suite.Require().Nil(err)
+
+	server.mutex.Lock()
+	server.Expired = true
+	server.mutex.Unlock()
+
+	for _, expected := range []icws.Message{icws.SessionExpiredMessage{}, icws.SessionReconnectedMessage{}} {
+		select {
+		case event := <-events:
+			suite.Require().IsType(expected, event.Message)
+		case <-time.After(5 * time.Second):
+			suite.FailNow("Timeout while waiting for an event")
+		}
+	}
+	suite.Assert().True(session.IsConnected())
+	suite.Assert().Equal(2, server.LoginCount())
+	suite.Require().Nil(session.Disconnect())
+}
+
+func (suite *SessionSuite) TestCanConnectConcurrently() {
+	server := NewFakeServer()
+	defer server.Close()
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:     []*url.URL{server.URL("127.0.0.1")},
+		UserID:      "agent",
+		Password:    "s3cr3t",
+		Application: "test",
+	})
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := session.GetVersion(); err != nil { // connects implicitly
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		suite.Require().Nil(err)
+	}
+	suite.Assert().True(session.IsConnected())
+	suite.Assert().Equal(1, server.LoginCount(), "All callers should share the same login")
+	suite.Require().Nil(session.Disconnect())
+}
+
+func (suite *SessionSuite) TestCanUseSessionConcurrently() {
+	session, server := suite.NewConnectedSession()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			_, err := session.GetVersion()
+			suite.Assert().Nil(err)
+		}()
+		go func() {
+			defer wg.Done()
+			err := session.Subscribe(icws.LicenseMessage{}, icws.LicenseSubscription{Licenses: []string{"I3_ACCESS_CLIENT"}})
+			suite.Assert().Nil(err)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = session.String()
+			_ = session.GetStatus()
+			_ = session.HasSupport("messaging")
+			session.ReleaseEvents(session.Events())
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := json.Marshal(session)
+			suite.Assert().Nil(err)
+		}()
+	}
+	wg.Wait()
+	suite.Assert().Equal(1, server.LoginCount())
+	suite.Require().Nil(session.Disconnect())
+	suite.Assert().Equal(icws.DisconnectedStatus, session.GetStatus())
+}
+
+func (suite *SessionSuite) TestCanRecoverFromSwitchoverConcurrently() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		options.SwitchoverRecovery = true
+	})
+
+	server.mutex.Lock()
+	server.Unavailable[server.URL("127.0.0.1").Host] = []string{"localhost"}
+	server.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := session.GetVersion()
+			suite.Assert().Nil(err)
+		}()
+	}
+	wg.Wait()
+	suite.Assert().True(session.IsConnected())
+	suite.Assert().Equal(2, server.LoginCount(), "All callers should share the same recovery")
+	suite.Require().Nil(session.Disconnect())
+}
+
+func (suite *SessionSuite) TestShouldNotRecoverWhileConnecting() {
+	server := NewFakeServer()
+	defer server.Close()
+	server.Handlers["PUT /icws/1234/messaging/subscriptions/status/user-statuses"] = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"errorId": "error.server.unavailable", "alternateHostList": ["localhost"]}`))
+	}
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:            []*url.URL{server.URL("127.0.0.1")},
+		UserID:             "agent",
+		Password:           "s3cr3t",
+		Application:        "test",
+		SwitchoverRecovery: true,
+		RequestAttempts:    1,
+	})
+	connected := make(chan error, 1)
+	go func() {
+		connected <- session.Connect()
+	}()
+	select {
+	case err := <-connected:
+		suite.Require().NotNil(err)
+		suite.Assert().True(errors.Is(err, errors.HTTPServiceUnavailable))
+		suite.Assert().False(session.IsConnected())
+		suite.Assert().Equal(1, server.LoginCount(), "Connect should not recover")
+	case <-time.After(5 * time.Second):
+		suite.Fail("Connect should not wait for itself")
+	}
+}
+
+func (suite *SessionSuite) TestShouldNotDeadlockWhenWatchingUsersDuringSwitchover() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		options.SwitchoverRecovery = true
+		options.RequestAttempts = 1
+	})
+
+	server.mutex.Lock()
+	server.Handlers["PUT /icws/1234/messaging/subscriptions/status/user-statuses"] = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"errorId": "error.server.unavailable", "alternateHostList": ["localhost"]}`))
+	}
+	server.mutex.Unlock()
+
+	watched := make(chan error, 1)
+	go func() {
+		watched <- session.WatchUserStatuses("supervisor")
+	}()
+	select {
+	case err := <-watched:
+		suite.Require().NotNil(err)
+		suite.Assert().Truef(errors.Is(err, icws.SwitchoverFailed), "Error should be a SwitchoverFailed, got %s", err)
+		suite.Assert().Equal(2, server.LoginCount(), "The Session should recover only once")
+	case <-time.After(5 * time.Second):
+		suite.Fail("The recovery should not wait for the watchers")
+	}
+}
+
+func (suite *SessionSuite) TestCanDispatchMessages() {
+	server := NewFakeServer()
+	defer server.Close()
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:     []*url.URL{server.URL("127.0.0.1")},
+		UserID:      "agent",
+		Password:    "s3cr3t",
+		Application: "test",
+	})
+	userStatuses := make(chan icws.UserStatusMessage, 2)
+	licenses := make(chan icws.LicenseMessage, 2)
+	session.OnUserStatus(func(message icws.UserStatusMessage) {
+		userStatuses <- message
+	})
+	session.OnUserStatus(func(message icws.UserStatusMessage) {
+		panic("handler failure")
+	})
+	icws.On(session, func(message icws.LicenseMessage) {
+		licenses <- message
+	})
+	suite.Require().Nil(session.Connect())
+	defer session.Disconnect()
+
+	server.StreamEvents <- "data: {\"__type\": \"urn:inin.com:status:userStatusMessage\", \"isDelta\": true, \"userStatusList\": [{\"userId\": \"agent\", \"statusId\": \"Available\"}]}\n\n"
+	server.StreamEvents <- "data: {\"__type\": \"urn:inin.com:status:licenseMessage\", \"isDelta\": true, \"licenseAssignedStatusList\": [{\"name\": \"I3_ACCESS_CLIENT\", \"isAssigned\": true}]}\n\n"
+	server.StreamEvents <- "data: {\"__type\": \"urn:inin.com:status:userStatusMessage\", \"isDelta\": true, \"userStatusList\": [{\"userId\": \"agent\", \"statusId\": \"Away\"}]}\n\n"
+
+	for i := 0; i < 2; i++ {
+		select {
+		case message := <-userStatuses:
+			suite.Require().Len(message.UserStatuses, 1)
+			suite.Assert().Equal("agent", message.UserStatuses[0].UserID)
+		case <-time.After(5 * time.Second):
+			suite.FailNow("Timeout while waiting for a UserStatusMessage")
+		}
+	}
+	select {
+	case message := <-licenses:
+		suite.Require().Len(message.Licenses, 1)
+		suite.Assert().Equal("I3_ACCESS_CLIENT", message.Licenses[0].Name)
+	case <-time.After(5 * time.Second):
+		suite.FailNow("Timeout while waiting for a LicenseMessage")
+	}
+}
+
+func (suite *SessionSuite) TestShouldDispatchMessagesInOrder() {
+	server := NewFakeServer()
+	defer server.Close()
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:         []*url.URL{server.URL("127.0.0.1")},
+		UserID:          "agent",
+		Password:        "s3cr3t",
+		Application:     "test",
+		EventBufferSize: 2,
+	})
+	statuses := make(chan string, 20)
+	session.OnUserStatus(func(message icws.UserStatusMessage) {
+		time.Sleep(time.Millisecond) // gives a chance to later messages to overtake this one
+		statuses <- message.UserStatuses[0].StatusID
+	})
+	suite.Require().Nil(session.Connect())
+	defer session.Disconnect()
+
+	for i := 0; i < 20; i++ {
+		server.StreamEvents <- fmt.Sprintf("data: {\"__type\": \"urn:inin.com:status:userStatusMessage\", \"isDelta\": true, \"userStatusList\": [{\"userId\": \"agent\", \"statusId\": \"S%d\"}]}\n\n", i)
+		server.StreamEvents <- "data: {\"__type\": \"urn:inin.com:status:licenseMessage\", \"isDelta\": true, \"licenseAssignedStatusList\": []}\n\n"
+	}
+	for i := 0; i < 20; i++ {
+		select {
+		case status := <-statuses:
+			suite.Require().Equal(fmt.Sprintf("S%d", i), status, "The messages should be processed in order")
+		case <-time.After(5 * time.Second):
+			suite.FailNow("Timeout while waiting for a UserStatusMessage")
+		}
+	}
+}
+
+func (suite *SessionSuite) TestCanFanOutEvents() {
+	server := NewFakeServer()
+	defer server.Close()
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:     []*url.URL{server.URL("127.0.0.1")},
+		UserID:      "agent",
+		Password:    "s3cr3t",
+		Application: "test",
+	})
+	first := session.Events()
+	suite.Assert().Equal(first, session.Events(), "Events should always give the same chan")
+	second := session.EventsWithOptions(icws.EventsOptions{})
+	dropOldest := session.EventsWithOptions(icws.EventsOptions{BufferSize: 1, Overflow: icws.DropOldestOnOverflow})
+	dropNewest := session.EventsWithOptions(icws.EventsOptions{BufferSize: 1, Overflow: icws.DropNewestOnOverflow})
+	suite.Require().Nil(session.Connect())
+
+	for _, id := range []string{"1", "2", "3"} {
+		server.StreamEvents <- "id: " + id + "\ndata: {\"__type\": \"urn:inin.com:status:userStatusMessage\", \"isDelta\": true, \"userStatusList\": []}\n\n"
+	}
+	for _, events := range []chan icws.EventSource{first, second} {
+		for _, id := range []string{"1", "2", "3"} {
+			select {
+			case event := <-events:
+				suite.Assert().Equal(id, event.ID)
+			case <-time.After(5 * time.Second):
+				suite.FailNow("Timeout while waiting for an event")
+			}
+		}
+	}
+	suite.Require().Eventually(func() bool { return session.TotalDroppedEvents() == 4 }, 5*time.Second, 10*time.Millisecond)
+	suite.Assert().Equal("3", (<-dropOldest).ID)
+	suite.Assert().Equal("1", (<-dropNewest).ID)
+	suite.Assert().Equal(uint64(2), session.DroppedEvents(dropOldest))
+	suite.Assert().Equal(uint64(2), session.DroppedEvents(dropNewest))
+	suite.Assert().Equal(uint64(0), session.DroppedEvents(first))
+
+	suite.Require().Nil(session.Disconnect())
+	_, ok := <-first
+	suite.Assert().False(ok, "The Events chan should be closed")
+}
@@ -0,0 +1,92 @@
+package icws
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gildas/go-errors"
+)
+
+// RemoteNumberSettings describes a connection to a remote phone number
+//
+// With a persistent connection, the remote number is called once and stays connected between interactions
+type RemoteNumberSettings struct {
+	RemoteNumber         string      `json:"remoteNumber"`
+	PersistentConnection bool        `json:"persistentConnection"`
+	SupportsMWI          bool        `json:"supportsMWI"`
+	SupportedMediaTypes  []MediaType `json:"supportedMediaTypes,omitempty"`
+	ReadyForInteractions bool        `json:"readyForInteractions"`
+}
+
+func init() {
+	stationSettingsRegistry.Add(RemoteNumberSettings{})
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (settings RemoteNumberSettings) GetType() string {
+	return "urn:inin.com:connection:remoteNumberSettings"
+}
+
+// Connect connects the Session to this Station
+//
+// implements StationSettings
+func (settings RemoteNumberSettings) Connect(context context.Context, session *Session) error {
+	return connectStation(context, session, settings)
+}
+
+// Disconnect disconnects the Session from this Station
+//
+// implements StationSettings
+func (settings RemoteNumberSettings) Disconnect(context context.Context, session *Session) error {
+	return disconnectStation(context, session)
+}
+
+// MediaTypes tells the media types the Station handles, calls if none are given
+//
+// implements StationSettings
+func (settings RemoteNumberSettings) MediaTypes() []MediaType {
+	return mediaTypesOrCalls(settings.SupportedMediaTypes)
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (settings RemoteNumberSettings) String() string {
+	return "remote number " + settings.RemoteNumber
+}
+
+// MarshalJSON marshals into JSON
+//
+// implements json.Marshaler
+func (settings RemoteNumberSettings) MarshalJSON() ([]byte, error) {
+	type surrogate RemoteNumberSettings
+	data, err := json.Marshal(struct {
+		Type string `json:"__type"`
+		surrogate
+	}{
+		Type:      settings.GetType(),
+		surrogate: surrogate(settings),
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// implements json.Unmarshaler
+func (settings *RemoteNumberSettings) UnmarshalJSON(payload []byte) (err error) {
+	type surrogate RemoteNumberSettings
+	var inner struct {
+		Type string `json:"__type"`
+		surrogate
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	if inner.Type != (RemoteNumberSettings{}.GetType()) {
+		return errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("__type", inner.Type))
+	}
+	*settings = RemoteNumberSettings(inner.surrogate)
+	return nil
+}
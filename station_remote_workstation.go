@@ -0,0 +1,88 @@
+package icws
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gildas/go-errors"
+)
+
+// RemoteWorkStationSettings describes a connection to a remote workstation
+type RemoteWorkStationSettings struct {
+	Workstation          string      `json:"workstation"`
+	SupportedMediaTypes  []MediaType `json:"supportedMediaTypes,omitempty"`
+	ReadyForInteractions bool        `json:"readyForInteractions"`
+}
+
+func init() {
+	stationSettingsRegistry.Add(RemoteWorkStationSettings{})
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (settings RemoteWorkStationSettings) GetType() string {
+	return "urn:inin.com:connection:remoteWorkstationSettings"
+}
+
+// Connect connects the Session to this Station
+//
+// implements StationSettings
+func (settings RemoteWorkStationSettings) Connect(context context.Context, session *Session) error {
+	return connectStation(context, session, settings)
+}
+
+// Disconnect disconnects the Session from this Station
+//
+// implements StationSettings
+func (settings RemoteWorkStationSettings) Disconnect(context context.Context, session *Session) error {
+	return disconnectStation(context, session)
+}
+
+// MediaTypes tells the media types the Station handles, calls if none are given
+//
+// implements StationSettings
+func (settings RemoteWorkStationSettings) MediaTypes() []MediaType {
+	return mediaTypesOrCalls(settings.SupportedMediaTypes)
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (settings RemoteWorkStationSettings) String() string {
+	return "remote workstation " + settings.Workstation
+}
+
+// MarshalJSON marshals into JSON
+//
+// implements json.Marshaler
+func (settings RemoteWorkStationSettings) MarshalJSON() ([]byte, error) {
+	type surrogate RemoteWorkStationSettings
+	data, err := json.Marshal(struct {
+		Type string `json:"__type"`
+		surrogate
+	}{
+		Type:      settings.GetType(),
+		surrogate: surrogate(settings),
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// implements json.Unmarshaler
+func (settings *RemoteWorkStationSettings) UnmarshalJSON(payload []byte) (err error) {
+	type surrogate RemoteWorkStationSettings
+	var inner struct {
+		Type string `json:"__type"`
+		surrogate
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	if inner.Type != (RemoteWorkStationSettings{}.GetType()) {
+		return errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("__type", inner.Type))
+	}
+	*settings = RemoteWorkStationSettings(inner.surrogate)
+	return nil
+}
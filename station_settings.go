@@ -1,19 +1,88 @@
 package icws
 
 import (
+	"context"
+
 	"github.com/gildas/go-core"
 	"github.com/gildas/go-errors"
 )
 
+// StationSettings describes how a Session connects to a Station
+//
+// The context cancels the requests sent to PureConnect.
 type StationSettings interface {
-	Connect(session *Session) error
-	Disconnect(session *Session) error
+	Connect(context context.Context, session *Session) error
+	Disconnect(context context.Context, session *Session) error
+	// MediaTypes tells the media types the Station handles
+	MediaTypes() []MediaType
 	core.TypeCarrier
 }
 
+// MediaType describes the type of media a Station can handle
+type MediaType int
+
+const (
+	NoMedia       MediaType = 0
+	CallMedia     MediaType = 1
+	ChatMedia     MediaType = 2
+	EmailMedia    MediaType = 3
+	GenericMedia  MediaType = 4
+	CallbackMedia MediaType = 5
+)
+
 // ConnectStation connects to a Station
+//
+// The licenses of the User are checked first, unless SessionOptions.SkipStationLicenses is true, see CheckStationLicenses.
+// The Session remembers the Station, it is disconnected when the Session disconnects
 func (session *Session) ConnectStation(settings StationSettings) error {
-	return settings.Connect(session)
+	return session.ConnectStationContext(session.Context, settings)
+}
+
+// ConnectStationContext connects to a Station
+//
+// The context cancels the requests
+func (session *Session) ConnectStationContext(context context.Context, settings StationSettings) error {
+	if !session.SkipStationLicenses {
+		if err := session.CheckStationLicensesContext(context, settings); err != nil {
+			return err
+		}
+	}
+	return session.connectStation(context, settings)
+}
+
+// connectStation connects to a Station and remembers it, without checking the licenses
+func (session *Session) connectStation(context context.Context, settings StationSettings) error {
+	if err := settings.Connect(context, session); err != nil {
+		return err
+	}
+	session.mutex.Lock()
+	session.StationSettings = settings
+	session.mutex.Unlock()
+	return nil
+}
+
+// DisconnectStation disconnects from the current Station, if any
+func (session *Session) DisconnectStation() error {
+	return session.DisconnectStationContext(session.Context)
+}
+
+// DisconnectStationContext disconnects from the current Station, if any
+//
+// The context cancels the request
+func (session *Session) DisconnectStationContext(context context.Context) error {
+	session.mutex.RLock()
+	settings := session.StationSettings
+	session.mutex.RUnlock()
+	if settings == nil {
+		return nil
+	}
+	if err := settings.Disconnect(context, session); err != nil {
+		return err
+	}
+	session.mutex.Lock()
+	session.StationSettings = nil
+	session.mutex.Unlock()
+	return nil
 }
 
 var stationSettingsRegistry = core.TypeRegistry{}
@@ -26,3 +95,19 @@ func UnmarshalStationSettings(payload []byte) (StationSettings, error) {
 	}
 	return value.(StationSettings), nil
 }
+
+// mediaTypesOrCalls gives the media types, calls if none are given
+func mediaTypesOrCalls(mediaTypes []MediaType) []MediaType {
+	if len(mediaTypes) == 0 {
+		return []MediaType{CallMedia}
+	}
+	return mediaTypes
+}
+
+func connectStation(context context.Context, session *Session, settings StationSettings) error {
+	return session.sendPutContext(context, "/connection/station", settings, nil)
+}
+
+func disconnectStation(context context.Context, session *Session) error {
+	return session.sendDeleteContext(context, "/connection/station")
+}
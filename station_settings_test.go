@@ -0,0 +1,111 @@
+package icws_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-icws"
+)
+
+func (suite *SessionSuite) TestCanConnectRemoteNumber() {
+	server := NewFakeServer()
+	defer server.Close()
+	server.Responses["GET /icws/1234/configuration/users/agent"] = `{"configurationId": {"id": "agent"}, "licenseProperties": {"licenseActive": true, "hasClientAccess": true, "mediaLevel": 0}}`
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:     []*url.URL{server.URL("127.0.0.1")},
+		UserID:      "agent",
+		Password:    "s3cr3t",
+		Application: "test",
+	})
+	suite.Require().Nil(session.Connect())
+
+	err := session.ConnectStation(icws.RemoteNumberSettings{
+		RemoteNumber:         "+13175551234",
+		PersistentConnection: true,
+		SupportsMWI:          true,
+		SupportedMediaTypes:  []icws.MediaType{icws.CallMedia},
+		ReadyForInteractions: true,
+	})
+	suite.Require().Nil(err)
+	suite.Assert().Equal(1, server.Count(http.MethodGet, "/icws/1234/configuration/users/agent"), "The licenses should be checked")
+	suite.Assert().JSONEq(
+		`{"__type": "urn:inin.com:connection:remoteNumberSettings", "remoteNumber": "+13175551234", "persistentConnection": true, "supportsMWI": true, "supportedMediaTypes": [1], "readyForInteractions": true}`,
+		server.Payload(http.MethodPut, "/icws/1234/connection/station"),
+	)
+	suite.Require().NotNil(session.StationSettings)
+
+	suite.Require().Nil(session.Disconnect())
+	suite.Assert().Equal(1, server.Count(http.MethodDelete, "/icws/1234/connection/station"))
+	suite.Assert().Nil(session.StationSettings)
+}
+
+func (suite *SessionSuite) TestShouldFailConnectingStationWithoutLicenses() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["GET /icws/1234/configuration/users/agent"] = `{"configurationId": {"id": "agent"}, "licenseProperties": {"licenseActive": true, "hasClientAccess": true, "mediaLevel": 0}}`
+	})
+
+	err := session.ConnectStation(icws.StationlessSettings{SupportedMediaTypes: []icws.MediaType{icws.ChatMedia, icws.EmailMedia}})
+	suite.Require().NotNil(err)
+	suite.Assert().Truef(errors.Is(err, icws.MissingLicense), "Error should be a MissingLicense, got %s", err)
+	suite.Assert().Equal(0, server.Count(http.MethodPut, "/icws/1234/connection/station"))
+	suite.Assert().Nil(session.StationSettings)
+
+	server.mutex.Lock()
+	server.Responses["GET /icws/1234/configuration/users/agent"] = `{"configurationId": {"id": "agent"}, "licenseProperties": {"licenseActive": true, "hasClientAccess": false}}`
+	server.mutex.Unlock()
+	err = session.ConnectStation(icws.WorkStationSettings{Workstation: "7001"})
+	suite.Require().NotNil(err)
+	suite.Assert().Truef(errors.Is(err, icws.MissingLicense), "Error should be a MissingLicense, got %s", err)
+}
+
+func (suite *SessionSuite) TestCanSkipStationLicenses() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		options.SkipStationLicenses = true
+	})
+
+	suite.Require().Nil(session.ConnectStation(icws.WorkStationSettings{Workstation: "7001"}))
+	suite.Assert().Equal(0, server.Count(http.MethodGet, "/icws/1234/configuration/users/agent"))
+	suite.Assert().Equal(1, server.Count(http.MethodPut, "/icws/1234/connection/station"))
+}
+
+func (suite *SessionSuite) TestStationlessShouldNotHandleCalls() {
+	suite.Assert().Empty(icws.StationlessSettings{}.MediaTypes())
+	suite.Assert().Equal(
+		[]icws.MediaType{icws.ChatMedia},
+		icws.StationlessSettings{SupportedMediaTypes: []icws.MediaType{icws.CallMedia, icws.ChatMedia}}.MediaTypes(),
+	)
+}
+
+func (suite *SessionSuite) TestCanUnmarshalStationSettings() {
+	expected := []icws.StationSettings{
+		icws.WorkStationSettings{Workstation: "7001", ReadyForInteractions: true},
+		icws.RemoteWorkStationSettings{Workstation: "remote-7001"},
+		icws.RemoteNumberSettings{RemoteNumber: "+13175551234", PersistentConnection: true},
+		icws.StationlessSettings{SupportedMediaTypes: []icws.MediaType{icws.ChatMedia, icws.EmailMedia}},
+	}
+	for _, settings := range expected {
+		payload, err := json.Marshal(settings)
+		suite.Require().Nil(err)
+		actual, err := icws.UnmarshalStationSettings(payload)
+		suite.Require().Nilf(err, "Failed to unmarshal %s", settings.GetType())
+		suite.Assert().Equal(settings.GetType(), actual.GetType())
+		suite.Assert().Equal(settings, dereference(actual))
+	}
+}
+
+func dereference(settings icws.StationSettings) icws.StationSettings {
+	switch value := settings.(type) {
+	case *icws.WorkStationSettings:
+		return *value
+	case *icws.RemoteWorkStationSettings:
+		return *value
+	case *icws.RemoteNumberSettings:
+		return *value
+	case *icws.StationlessSettings:
+		return *value
+	}
+	return settings
+}
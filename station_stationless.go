@@ -0,0 +1,97 @@
+package icws
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gildas/go-errors"
+)
+
+// StationlessSettings describes a connection without any Station
+//
+// Such a connection can handle non-call interactions only (chats, emails, etc)
+type StationlessSettings struct {
+	SupportedMediaTypes  []MediaType `json:"supportedMediaTypes,omitempty"`
+	ReadyForInteractions bool        `json:"readyForInteractions"`
+}
+
+func init() {
+	stationSettingsRegistry.Add(StationlessSettings{})
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (settings StationlessSettings) GetType() string {
+	return "urn:inin.com:connection:stationlessSettings"
+}
+
+// Connect connects the Session to this Station
+//
+// implements StationSettings
+func (settings StationlessSettings) Connect(context context.Context, session *Session) error {
+	return connectStation(context, session, settings)
+}
+
+// Disconnect disconnects the Session from this Station
+//
+// implements StationSettings
+func (settings StationlessSettings) Disconnect(context context.Context, session *Session) error {
+	return disconnectStation(context, session)
+}
+
+// MediaTypes tells the media types the Station handles
+//
+// As there is no Station to take calls, call media is never included.
+//
+// implements StationSettings
+func (settings StationlessSettings) MediaTypes() []MediaType {
+	mediaTypes := make([]MediaType, 0, len(settings.SupportedMediaTypes))
+	for _, mediaType := range settings.SupportedMediaTypes {
+		if mediaType != CallMedia {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	return mediaTypes
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (settings StationlessSettings) String() string {
+	return "stationless"
+}
+
+// MarshalJSON marshals into JSON
+//
+// implements json.Marshaler
+func (settings StationlessSettings) MarshalJSON() ([]byte, error) {
+	type surrogate StationlessSettings
+	data, err := json.Marshal(struct {
+		Type string `json:"__type"`
+		surrogate
+	}{
+		Type:      settings.GetType(),
+		surrogate: surrogate(settings),
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// implements json.Unmarshaler
+func (settings *StationlessSettings) UnmarshalJSON(payload []byte) (err error) {
+	type surrogate StationlessSettings
+	var inner struct {
+		Type string `json:"__type"`
+		surrogate
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	if inner.Type != (StationlessSettings{}.GetType()) {
+		return errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("__type", inner.Type))
+	}
+	*settings = StationlessSettings(inner.surrogate)
+	return nil
+}
@@ -1,12 +1,17 @@
 package icws
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/gildas/go-errors"
 )
 
+// WorkStationSettings describes a connection to a workstation (SIP phone or soft phone)
 type WorkStationSettings struct {
+	Workstation          string      `json:"workstation"`
+	SupportedMediaTypes  []MediaType `json:"supportedMediaTypes,omitempty"`
+	ReadyForInteractions bool        `json:"readyForInteractions"`
 }
 
 func init() {
@@ -20,17 +25,45 @@ func (settings WorkStationSettings) GetType() string {
 	return "urn:inin.com:connection:workstationSettings"
 }
 
+// Connect connects the Session to this Station
+//
+// implements StationSettings
+func (settings WorkStationSettings) Connect(context context.Context, session *Session) error {
+	return connectStation(context, session, settings)
+}
+
+// Disconnect disconnects the Session from this Station
+//
+// implements StationSettings
+func (settings WorkStationSettings) Disconnect(context context.Context, session *Session) error {
+	return disconnectStation(context, session)
+}
+
+// MediaTypes tells the media types the Station handles, calls if none are given
+//
+// implements StationSettings
+func (settings WorkStationSettings) MediaTypes() []MediaType {
+	return mediaTypesOrCalls(settings.SupportedMediaTypes)
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (settings WorkStationSettings) String() string {
+	return "workstation " + settings.Workstation
+}
+
 // MarshalJSON marshals into JSON
 //
 // implements json.Marshaler
-func (message WorkStationSettings) MarshalJSON() ([]byte, error) {
+func (settings WorkStationSettings) MarshalJSON() ([]byte, error) {
 	type surrogate WorkStationSettings
 	data, err := json.Marshal(struct {
 		Type string `json:"__type"`
 		surrogate
 	}{
-		Type:      message.GetType(),
-		surrogate: surrogate(message),
+		Type:      settings.GetType(),
+		surrogate: surrogate(settings),
 	})
 	return data, errors.JSONMarshalError.Wrap(err)
 }
@@ -38,7 +71,7 @@ func (message WorkStationSettings) MarshalJSON() ([]byte, error) {
 // UnmarshalJSON unmarshals from JSON
 //
 // implements json.Unmarshaler
-func (message *WorkStationSettings) UnmarshalJSON(payload []byte) (err error) {
+func (settings *WorkStationSettings) UnmarshalJSON(payload []byte) (err error) {
 	type surrogate WorkStationSettings
 	var inner struct {
 		Type string `json:"__type"`
@@ -50,6 +83,6 @@ func (message *WorkStationSettings) UnmarshalJSON(payload []byte) (err error) {
 	if inner.Type != (WorkStationSettings{}.GetType()) {
 		return errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("__type", inner.Type))
 	}
-	*message = WorkStationSettings(inner.surrogate)
+	*settings = WorkStationSettings(inner.surrogate)
 	return nil
 }
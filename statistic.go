@@ -0,0 +1,383 @@
+package icws
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+)
+
+// StatisticKey identifies a statistic and the values of its parameters
+type StatisticKey struct {
+	Identifier string               `json:"statisticIdentifier"`
+	Parameters []StatisticParameter `json:"parameterValueItems,omitempty"`
+}
+
+// StatisticParameter is the value of a parameter of a statistic (workgroup, interval, etc)
+type StatisticParameter struct {
+	TypeID string `json:"parameterTypeId"`
+	Value  string `json:"value"`
+}
+
+// StatisticValue is the value of a statistic
+type StatisticValue interface {
+	core.TypeCarrier
+	// String gets a text representation of the value
+	String() string
+}
+
+// StatisticIntValue is an integer statistic value
+type StatisticIntValue struct {
+	Value int64 `json:"value"`
+}
+
+// StatisticDurationValue is a duration statistic value
+type StatisticDurationValue struct {
+	Value time.Duration `json:"-"`
+}
+
+// StatisticPercentValue is a percent statistic value
+type StatisticPercentValue struct {
+	Value float64 `json:"value"`
+}
+
+// StatisticStringValue is a text statistic value
+type StatisticStringValue struct {
+	Value string `json:"value"`
+}
+
+// StatisticValueList is a list of statistic values
+type StatisticValueList struct {
+	Values []StatisticValue `json:"-"`
+}
+
+// Well-known statistic parameter types
+const (
+	WorkgroupStatisticParameter = "ININ.People.WorkgroupStats:Workgroup"
+	UserStatisticParameter      = "ININ.People.AgentStats:User"
+	IntervalStatisticParameter  = "ININ.Queue:Interval"
+)
+
+// Well-known statistic intervals
+const (
+	CurrentPeriodInterval = "CurrentPeriod"
+	CurrentShiftInterval  = "CurrentShift"
+	PreviousShiftInterval = "PreviousShift"
+)
+
+var statisticValueRegistry = core.TypeRegistry{}
+
+func init() {
+	statisticValueRegistry.Add(
+		StatisticIntValue{},
+		StatisticDurationValue{},
+		StatisticPercentValue{},
+		StatisticStringValue{},
+		StatisticValueList{},
+	)
+}
+
+// NewStatisticKey creates a new StatisticKey
+func NewStatisticKey(identifier string, parameters ...StatisticParameter) StatisticKey {
+	return StatisticKey{Identifier: identifier, Parameters: parameters}
+}
+
+// NewWorkgroupParameter creates a StatisticParameter for a workgroup
+func NewWorkgroupParameter(workgroup string) StatisticParameter {
+	return StatisticParameter{TypeID: WorkgroupStatisticParameter, Value: workgroup}
+}
+
+// NewUserParameter creates a StatisticParameter for a user
+func NewUserParameter(userID string) StatisticParameter {
+	return StatisticParameter{TypeID: UserStatisticParameter, Value: userID}
+}
+
+// NewIntervalParameter creates a StatisticParameter for an interval
+func NewIntervalParameter(interval string) StatisticParameter {
+	return StatisticParameter{TypeID: IntervalStatisticParameter, Value: interval}
+}
+
+// UnmarshalStatisticValue unmarshals from a JSON payload
+func UnmarshalStatisticValue(payload []byte) (StatisticValue, error) {
+	value, err := statisticValueRegistry.UnmarshalJSON(payload, "__type")
+	if err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	return value.(StatisticValue), nil
+}
+
+// String gets a text representation, suitable as a map key
+//
+// The parameters are sorted: keys with the same parameters in a different order give the same string.
+//
+// implements fmt.Stringer
+func (key StatisticKey) String() string {
+	parameters := make([]string, len(key.Parameters))
+	for i, parameter := range key.Parameters {
+		parameters[i] = parameter.TypeID + "=" + parameter.Value
+	}
+	sort.Strings(parameters)
+	if len(parameters) == 0 {
+		return key.Identifier
+	}
+	return key.Identifier + "[" + strings.Join(parameters, ",") + "]"
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (value StatisticIntValue) GetType() string {
+	return "urn:inin.com:statistics:statisticIntValue"
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (value StatisticIntValue) String() string {
+	return strconv.FormatInt(value.Value, 10)
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (value StatisticDurationValue) GetType() string {
+	return "urn:inin.com:statistics:statisticDurationValue"
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (value StatisticDurationValue) String() string {
+	return value.Value.String()
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (value StatisticPercentValue) GetType() string {
+	return "urn:inin.com:statistics:statisticPercentValue"
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (value StatisticPercentValue) String() string {
+	return strconv.FormatFloat(value.Value, 'f', -1, 64) + "%"
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (value StatisticStringValue) GetType() string {
+	return "urn:inin.com:statistics:statisticStringValue"
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (value StatisticStringValue) String() string {
+	return value.Value
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (value StatisticValueList) GetType() string {
+	return "urn:inin.com:statistics:statisticValueList"
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (value StatisticValueList) String() string {
+	values := make([]string, len(value.Values))
+	for i, item := range value.Values {
+		values[i] = item.String()
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// MarshalJSON marshals into JSON
+//
+// implements json.Marshaler
+func (value StatisticIntValue) MarshalJSON() ([]byte, error) {
+	type surrogate StatisticIntValue
+	data, err := json.Marshal(struct {
+		Type string `json:"__type"`
+		surrogate
+	}{
+		Type:      value.GetType(),
+		surrogate: surrogate(value),
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// implements json.Unmarshaler
+func (value *StatisticIntValue) UnmarshalJSON(payload []byte) (err error) {
+	type surrogate StatisticIntValue
+	var inner struct {
+		Type string `json:"__type"`
+		surrogate
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	if inner.Type != (StatisticIntValue{}.GetType()) {
+		return errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("__type", inner.Type))
+	}
+	*value = StatisticIntValue(inner.surrogate)
+	return nil
+}
+
+// MarshalJSON marshals into JSON
+//
+// PureConnect sends durations in milliseconds.
+//
+// implements json.Marshaler
+func (value StatisticDurationValue) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(struct {
+		Type  string `json:"__type"`
+		Value int64  `json:"value"`
+	}{
+		Type:  value.GetType(),
+		Value: value.Value.Milliseconds(),
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// implements json.Unmarshaler
+func (value *StatisticDurationValue) UnmarshalJSON(payload []byte) (err error) {
+	var inner struct {
+		Type  string `json:"__type"`
+		Value int64  `json:"value"`
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	if inner.Type != (StatisticDurationValue{}.GetType()) {
+		return errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("__type", inner.Type))
+	}
+	value.Value = time.Duration(inner.Value) * time.Millisecond
+	return nil
+}
+
+// MarshalJSON marshals into JSON
+//
+// implements json.Marshaler
+func (value StatisticPercentValue) MarshalJSON() ([]byte, error) {
+	type surrogate StatisticPercentValue
+	data, err := json.Marshal(struct {
+		Type string `json:"__type"`
+		surrogate
+	}{
+		Type:      value.GetType(),
+		surrogate: surrogate(value),
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// implements json.Unmarshaler
+func (value *StatisticPercentValue) UnmarshalJSON(payload []byte) (err error) {
+	type surrogate StatisticPercentValue
+	var inner struct {
+		Type string `json:"__type"`
+		surrogate
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	if inner.Type != (StatisticPercentValue{}.GetType()) {
+		return errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("__type", inner.Type))
+	}
+	*value = StatisticPercentValue(inner.surrogate)
+	return nil
+}
+
+// MarshalJSON marshals into JSON
+//
+// implements json.Marshaler
+func (value StatisticStringValue) MarshalJSON() ([]byte, error) {
+	type surrogate StatisticStringValue
+	data, err := json.Marshal(struct {
+		Type string `json:"__type"`
+		surrogate
+	}{
+		Type:      value.GetType(),
+		surrogate: surrogate(value),
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// implements json.Unmarshaler
+func (value *StatisticStringValue) UnmarshalJSON(payload []byte) (err error) {
+	type surrogate StatisticStringValue
+	var inner struct {
+		Type string `json:"__type"`
+		surrogate
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	if inner.Type != (StatisticStringValue{}.GetType()) {
+		return errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("__type", inner.Type))
+	}
+	*value = StatisticStringValue(inner.surrogate)
+	return nil
+}
+
+// MarshalJSON marshals into JSON
+//
+// implements json.Marshaler
+func (value StatisticValueList) MarshalJSON() ([]byte, error) {
+	values := value.Values
+	if values == nil {
+		values = []StatisticValue{}
+	}
+	data, err := json.Marshal(struct {
+		Type   string           `json:"__type"`
+		Values []StatisticValue `json:"values"`
+	}{
+		Type:   value.GetType(),
+		Values: values,
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// Values of unknown types are skipped.
+//
+// implements json.Unmarshaler
+func (value *StatisticValueList) UnmarshalJSON(payload []byte) (err error) {
+	var inner struct {
+		Type   string            `json:"__type"`
+		Values []json.RawMessage `json:"values"`
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	if inner.Type != (StatisticValueList{}.GetType()) {
+		return errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("__type", inner.Type))
+	}
+	value.Values = make([]StatisticValue, 0, len(inner.Values))
+	for _, raw := range inner.Values {
+		item, err := UnmarshalStatisticValue(raw)
+		if err != nil {
+			continue
+		}
+		value.Values = append(value.Values, item)
+	}
+	return nil
+}
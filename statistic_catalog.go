@@ -0,0 +1,90 @@
+package icws
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// StatisticCatalog describes the statistics PureConnect provides
+type StatisticCatalog struct {
+	Categories []StatisticCategory `json:"statisticCategories"`
+}
+
+// StatisticCategory is a category of statistics
+type StatisticCategory struct {
+	ID            string                `json:"id"`
+	DisplayString string                `json:"displayString"`
+	Definitions   []StatisticDefinition `json:"statisticDefinitions"`
+}
+
+// StatisticDefinition describes a statistic
+type StatisticDefinition struct {
+	Identifier         string                         `json:"statisticIdentifier"`
+	DisplayString      string                         `json:"displayString"`
+	Description        string                         `json:"description"`
+	ValueType          string                         `json:"statisticValueType"`
+	RequiredParameters []StatisticParameterDefinition `json:"requiredParameters"`
+}
+
+// StatisticParameterDefinition describes a parameter of a statistic
+type StatisticParameterDefinition struct {
+	TypeID        string `json:"parameterTypeId"`
+	DisplayString string `json:"displayString"`
+}
+
+// GetStatisticCatalog retrieves the catalog of statistics
+//
+// If categories are given, only these categories are retrieved
+func (session *Session) GetStatisticCatalog(categories ...string) (*StatisticCatalog, error) {
+	return session.GetStatisticCatalogContext(session.Context, categories...)
+}
+
+// GetStatisticCatalogContext retrieves the catalog of statistics
+//
+// The context cancels the request
+func (session *Session) GetStatisticCatalogContext(context context.Context, categories ...string) (*StatisticCatalog, error) {
+	var parameters map[string]string
+	if len(categories) > 0 {
+		parameters = map[string]string{"categoryIds": strings.Join(categories, ",")}
+	}
+	catalog := StatisticCatalog{}
+	if _, err := session.sendContext(context, http.MethodGet, "/statistics/statistic-catalog", nil, parameters, nil, &catalog); err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+// Lookup finds the definition of a statistic
+func (catalog StatisticCatalog) Lookup(identifier string) (StatisticDefinition, bool) {
+	for _, category := range catalog.Categories {
+		for _, definition := range category.Definitions {
+			if definition.Identifier == identifier {
+				return definition, true
+			}
+		}
+	}
+	return StatisticDefinition{}, false
+}
+
+// GetID tells the ID
+//
+// implements Identifiable
+func (definition StatisticDefinition) GetID() string {
+	return definition.Identifier
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (definition StatisticDefinition) String() string {
+	if len(definition.DisplayString) > 0 {
+		return definition.DisplayString
+	}
+	return definition.Identifier
+}
+
+// Key creates a StatisticKey for this statistic
+func (definition StatisticDefinition) Key(parameters ...StatisticParameter) StatisticKey {
+	return NewStatisticKey(definition.Identifier, parameters...)
+}
@@ -0,0 +1,150 @@
+package icws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/gildas/go-errors"
+)
+
+// StatisticValueMessage describes the changes of the subscribed statistics
+type StatisticValueMessage struct {
+	Changes []StatisticValueChange `json:"statisticValueChanges"`
+	IsDelta bool                   `json:"isDelta"`
+}
+
+// StatisticValueChange is the new value of a statistic
+//
+// Value is nil when the statistic has no value (e.g.: not available)
+type StatisticValueChange struct {
+	Key   StatisticKey   `json:"statisticKey"`
+	Value StatisticValue `json:"statisticValue"`
+}
+
+// StatisticValueSubscription describes a Statistic Value Subscription Request
+type StatisticValueSubscription struct {
+	Keys []StatisticKey `json:"statisticKeys"`
+}
+
+func init() {
+	messageRegistry.Add(StatisticValueMessage{})
+}
+
+// GetType tells the JSON type
+//
+// implements core.TypeCarrier
+func (message StatisticValueMessage) GetType() string {
+	return "urn:inin.com:statistics:statisticValueMessage"
+}
+
+// Subscribe subscribe a Session to this type of messages
+//
+// implements Subscriber
+func (message StatisticValueMessage) Subscribe(context context.Context, session *Session, payload interface{}) error {
+	return session.sendPutContext(context, "/messaging/subscriptions/statistics/statistic-values", payload, nil)
+}
+
+// Subscribe subscribe a Session to this type of messages
+//
+// implements Unsubscriber
+func (message StatisticValueMessage) Unsubscribe(context context.Context, session *Session) error {
+	return session.sendDeleteContext(context, "/messaging/subscriptions/statistics/statistic-values")
+}
+
+// SubscribeStatistics subscribes the Session to the values of the given statistics
+//
+// PureConnect allows only one statistic subscription per session, subscribing again replaces the statistics
+func (session *Session) SubscribeStatistics(keys ...StatisticKey) (*SubscriptionHandle, error) {
+	return session.SubscribeStatisticsContext(session.Context, keys...)
+}
+
+// SubscribeStatisticsContext subscribes the Session to the values of the given statistics
+//
+// The context cancels the request
+func (session *Session) SubscribeStatisticsContext(context context.Context, keys ...StatisticKey) (*SubscriptionHandle, error) {
+	return session.SubscribeWithHandleContext(context, StatisticValueMessage{}, StatisticValueSubscription{Keys: keys})
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (message StatisticValueMessage) String() string {
+	sb := strings.Builder{}
+	sb.WriteString("Statistics: [")
+	for _, change := range message.Changes {
+		sb.WriteString("{")
+		sb.WriteString(change.String())
+		sb.WriteString("} ")
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (change StatisticValueChange) String() string {
+	if change.Value == nil {
+		return change.Key.String() + ": n/a"
+	}
+	return change.Key.String() + ": " + change.Value.String()
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// A value of an unknown type is left nil, so it does not fail the other changes of the message.
+//
+// implements json.Unmarshaler
+func (change *StatisticValueChange) UnmarshalJSON(payload []byte) (err error) {
+	var inner struct {
+		Key   StatisticKey    `json:"statisticKey"`
+		Value json.RawMessage `json:"statisticValue"`
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	change.Key = inner.Key
+	change.Value = nil
+	if len(inner.Value) > 0 && !bytes.Equal(inner.Value, []byte("null")) {
+		if change.Value, err = UnmarshalStatisticValue(inner.Value); err != nil {
+			change.Value = nil
+		}
+	}
+	return nil
+}
+
+// MarshalJSON marshals into JSON
+//
+// implements json.Marshaler
+func (message StatisticValueMessage) MarshalJSON() ([]byte, error) {
+	type surrogate StatisticValueMessage
+	data, err := json.Marshal(struct {
+		Type string `json:"__type"`
+		surrogate
+	}{
+		Type:      message.GetType(),
+		surrogate: surrogate(message),
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON unmarshals from JSON
+//
+// implements json.Unmarshaler
+func (message *StatisticValueMessage) UnmarshalJSON(payload []byte) (err error) {
+	type surrogate StatisticValueMessage
+	var inner struct {
+		Type string `json:"__type"`
+		surrogate
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	if inner.Type != (StatisticValueMessage{}.GetType()) {
+		return errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("__type", inner.Type))
+	}
+	*message = StatisticValueMessage(inner.surrogate)
+	return nil
+}
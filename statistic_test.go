@@ -0,0 +1,69 @@
+package icws_test
+
+import (
+	"net/http"
+
+	"github.com/gildas/go-icws"
+)
+
+func (suite *SessionSuite) TestCanSubscribeToStatistics() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["GET /icws/1234/statistics/statistic-catalog"] = `{"statisticCategories": [
+			{"id": "inin.workgroup", "displayString": "Workgroup", "statisticDefinitions": [
+				{"statisticIdentifier": "inin.workgroup:NumberOfAgentsLoggedIn", "displayString": "Agents Logged In", "requiredParameters": [{"parameterTypeId": "ININ.People.WorkgroupStats:Workgroup"}]}
+			]}
+		]}`
+	})
+
+	catalog, err := session.GetStatisticCatalog()
+	suite.Require().Nil(err)
+	definition, found := catalog.Lookup("inin.workgroup:NumberOfAgentsLoggedIn")
+	suite.Require().True(found)
+	suite.Assert().Equal("Agents Logged In", definition.String())
+	_, found = catalog.Lookup("inin.workgroup:Unknown")
+	suite.Assert().False(found)
+
+	_, err = session.SubscribeStatistics(definition.Key(icws.NewWorkgroupParameter("Sales"), icws.NewIntervalParameter(icws.CurrentShiftInterval)))
+	suite.Require().Nil(err)
+	suite.Assert().JSONEq(
+		`{"statisticKeys": [{"statisticIdentifier": "inin.workgroup:NumberOfAgentsLoggedIn", "parameterValueItems": [
+			{"parameterTypeId": "ININ.People.WorkgroupStats:Workgroup", "value": "Sales"},
+			{"parameterTypeId": "ININ.Queue:Interval", "value": "CurrentShift"}
+		]}]}`,
+		server.Payload(http.MethodPut, "/icws/1234/messaging/subscriptions/statistics/statistic-values"),
+	)
+}
+
+func (suite *SessionSuite) TestCanGetStatisticCatalogCategories() {
+	query := make(chan string, 1)
+	session, _ := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Handlers["GET /icws/1234/statistics/statistic-catalog"] = func(w http.ResponseWriter, r *http.Request) {
+			query <- r.URL.Query().Get("categoryIds")
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"statisticCategories": [
+				{"id": "inin.workgroup", "displayString": "Workgroup", "statisticDefinitions": [
+					{"statisticIdentifier": "inin.workgroup:NumberOfAgentsLoggedIn", "displayString": "Agents Logged In", "description": "Agents logged in the workgroup", "statisticValueType": "urn:inin.com:statistics:statisticIntValue", "requiredParameters": [{"parameterTypeId": "ININ.People.WorkgroupStats:Workgroup", "displayString": "Workgroup"}]}
+				]},
+				{"id": "inin.user", "displayString": "User", "statisticDefinitions": [
+					{"statisticIdentifier": "inin.user:Status"}
+				]}
+			]}`))
+		}
+	})
+
+	catalog, err := session.GetStatisticCatalog("inin.workgroup", "inin.user")
+	suite.Require().Nil(err)
+	suite.Assert().Equal("inin.workgroup,inin.user", <-query)
+	suite.Require().Len(catalog.Categories, 2)
+	suite.Assert().Equal("Workgroup", catalog.Categories[0].DisplayString)
+	definition := catalog.Categories[0].Definitions[0]
+	suite.Assert().Equal("inin.workgroup:NumberOfAgentsLoggedIn", definition.GetID())
+	suite.Assert().Equal("urn:inin.com:statistics:statisticIntValue", definition.ValueType)
+	suite.Require().Len(definition.RequiredParameters, 1)
+	suite.Assert().Equal(icws.WorkgroupStatisticParameter, definition.RequiredParameters[0].TypeID)
+	suite.Assert().Equal("inin.user:Status", catalog.Categories[1].Definitions[0].String(), "A definition without display string should show its identifier")
+
+	_, err = session.GetStatisticCatalog()
+	suite.Require().Nil(err)
+	suite.Assert().Empty(<-query, "All categories should be retrieved")
+}
@@ -1,5 +1,10 @@
 package icws
 
+import (
+	"context"
+	"net/url"
+)
+
 // StatusMessage describes a Status Message
 type StatusMessage struct {
 	ID              string `json:"statusId"`
@@ -24,3 +29,19 @@ type StatusMessage struct {
 func (status StatusMessage) GetID() string {
 	return status.ID
 }
+
+// GetStatusMessage retrieves a Status Message
+func (session *Session) GetStatusMessage(statusID string) (*StatusMessage, error) {
+	return session.GetStatusMessageContext(session.Context, statusID)
+}
+
+// GetStatusMessageContext retrieves a Status Message
+//
+// The context cancels the request
+func (session *Session) GetStatusMessageContext(context context.Context, statusID string) (*StatusMessage, error) {
+	statusMessage := StatusMessage{}
+	if err := session.sendGetContext(context, "/status/status-messages/"+url.PathEscape(statusID), &statusMessage); err != nil {
+		return nil, err
+	}
+	return &statusMessage, nil
+}
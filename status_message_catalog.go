@@ -0,0 +1,139 @@
+package icws
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/gildas/go-errors"
+)
+
+// StatusMessageCatalog keeps the Status Messages of PureConnect up to date
+//
+// The catalog applies the StatusMessageMessage deltas in the order they are received.
+type StatusMessageCatalog struct {
+	messageFeed[StatusMessageMessage]
+	messages map[string]StatusMessage
+	mutex    sync.RWMutex
+}
+
+// NewStatusMessageCatalog creates a new StatusMessageCatalog
+//
+// If session is not nil, the Session is subscribed to the Status Messages and they are applied to the catalog.
+func NewStatusMessageCatalog(session *Session) (*StatusMessageCatalog, error) {
+	if session == nil {
+		return NewStatusMessageCatalogContext(context.Background(), nil)
+	}
+	return NewStatusMessageCatalogContext(session.Context, session)
+}
+
+// NewStatusMessageCatalogContext creates a new StatusMessageCatalog
+//
+// The context cancels the subscription request
+func NewStatusMessageCatalogContext(context context.Context, session *Session) (*StatusMessageCatalog, error) {
+	catalog := &StatusMessageCatalog{messages: map[string]StatusMessage{}}
+	if session == nil {
+		return catalog, nil
+	}
+	catalog.start(session, catalog.Apply)
+	if err := session.SubscribeContext(context, StatusMessageMessage{}, struct{}{}); err != nil {
+		catalog.stop()
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// Apply applies the changes of a StatusMessageMessage
+func (catalog *StatusMessageCatalog) Apply(message StatusMessageMessage) {
+	catalog.mutex.Lock()
+	defer catalog.mutex.Unlock()
+
+	if !message.IsDelta {
+		catalog.messages = map[string]StatusMessage{}
+	}
+	for _, statusMessage := range message.AddedMessages {
+		catalog.messages[statusMessage.ID] = statusMessage
+	}
+	for _, statusMessage := range message.ChangedMessages {
+		catalog.messages[statusMessage.ID] = statusMessage
+	}
+	for _, statusID := range message.RemovedMessages {
+		delete(catalog.messages, statusID)
+	}
+}
+
+// Get gives the Status Message with the given ID
+func (catalog *StatusMessageCatalog) Get(statusID string) (StatusMessage, bool) {
+	catalog.mutex.RLock()
+	defer catalog.mutex.RUnlock()
+	statusMessage, found := catalog.messages[statusID]
+	return statusMessage, found
+}
+
+// All gives all the Status Messages, sorted by their ID
+func (catalog *StatusMessageCatalog) All() []StatusMessage {
+	catalog.mutex.RLock()
+	defer catalog.mutex.RUnlock()
+	statusMessages := make([]StatusMessage, 0, len(catalog.messages))
+	for _, statusMessage := range catalog.messages {
+		statusMessages = append(statusMessages, statusMessage)
+	}
+	sort.Slice(statusMessages, func(i, j int) bool { return statusMessages[i].ID < statusMessages[j].ID })
+	return statusMessages
+}
+
+// Resolve gives the display text and the icon of the status of a User
+//
+// If the status is unknown, its ID is used as text and the icon is empty
+func (catalog *StatusMessageCatalog) Resolve(status UserStatus) (text string, iconURI string) {
+	if statusMessage, found := catalog.Get(status.StatusID); found {
+		return statusMessage.Text, statusMessage.IconURI
+	}
+	return status.StatusID, ""
+}
+
+// AllowedFor gives the Status Messages the given User can use
+//
+// Status Messages unknown to the catalog are ignored
+func (catalog *StatusMessageCatalog) AllowedFor(userID string) ([]StatusMessage, error) {
+	if catalog.session == nil {
+		return nil, errors.ArgumentMissing.With("session")
+	}
+	return catalog.AllowedForContext(catalog.session.Context, userID)
+}
+
+// AllowedForContext gives the Status Messages the given User can use
+//
+// The context cancels the request
+func (catalog *StatusMessageCatalog) AllowedForContext(context context.Context, userID string) ([]StatusMessage, error) {
+	if catalog.session == nil {
+		return nil, errors.ArgumentMissing.With("session")
+	}
+	results := struct {
+		StatusIDs []string `json:"statusMessages"`
+	}{}
+	if err := catalog.session.sendGetContext(context, "/status/status-messages-user-access/"+url.PathEscape(userID), &results); err != nil {
+		return nil, err
+	}
+	statusMessages := make([]StatusMessage, 0, len(results.StatusIDs))
+	for _, statusID := range results.StatusIDs {
+		if statusMessage, found := catalog.Get(statusID); found {
+			statusMessages = append(statusMessages, statusMessage)
+		}
+	}
+	return statusMessages, nil
+}
+
+// Close unsubscribes the Session from the Status Messages and stops feeding the catalog
+func (catalog *StatusMessageCatalog) Close() error {
+	if catalog.session == nil {
+		return nil
+	}
+	var err error
+	if catalog.session.IsConnected() {
+		err = catalog.session.Unsubscribe(StatusMessageMessage{})
+	}
+	catalog.stop()
+	return err
+}
@@ -0,0 +1,63 @@
+package icws_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gildas/go-icws"
+)
+
+func (suite *SessionSuite) TestCanApplyStatusMessageDeltas() {
+	catalog, err := icws.NewStatusMessageCatalog(nil)
+	suite.Require().Nil(err)
+	catalog.Apply(icws.StatusMessageMessage{
+		IsDelta: false,
+		AddedMessages: []icws.StatusMessage{
+			{ID: "Available", Text: "Available", IconURI: "status/available.png"},
+			{ID: "Away", Text: "Away from desk", IconURI: "status/away.png"},
+		},
+	})
+	suite.Assert().Len(catalog.All(), 2)
+
+	catalog.Apply(icws.StatusMessageMessage{
+		IsDelta:         true,
+		AddedMessages:   []icws.StatusMessage{{ID: "Lunch", Text: "At Lunch"}},
+		ChangedMessages: []icws.StatusMessage{{ID: "Away", Text: "Away", IconURI: "status/away2.png"}},
+		RemovedMessages: []string{"Available"},
+	})
+	all := catalog.All()
+	suite.Require().Len(all, 2)
+	suite.Assert().Equal("Away", all[0].ID)
+	suite.Assert().Equal("Lunch", all[1].ID)
+
+	text, icon := catalog.Resolve(icws.UserStatus{StatusID: "Away"})
+	suite.Assert().Equal("Away", text)
+	suite.Assert().Equal("status/away2.png", icon)
+	text, icon = catalog.Resolve(icws.UserStatus{StatusID: "Unknown"})
+	suite.Assert().Equal("Unknown", text)
+	suite.Assert().Empty(icon)
+
+	catalog.Apply(icws.StatusMessageMessage{IsDelta: false})
+	suite.Assert().Empty(catalog.All())
+}
+
+func (suite *SessionSuite) TestCanFeedStatusMessageCatalog() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["GET /icws/1234/status/status-messages-user-access/agent"] = `{"statusMessages": ["Available", "Unknown"]}`
+	})
+
+	catalog, err := icws.NewStatusMessageCatalog(session)
+	suite.Require().Nil(err)
+	suite.Assert().Equal(1, server.Count(http.MethodPut, "/icws/1234/messaging/subscriptions/status/status-messages"))
+
+	server.StreamEvents <- "data: {\"__type\": \"urn:inin.com:status:statusMessagesMessage\", \"isDelta\": false, \"statusMessagesAdded\": [{\"statusId\": \"Available\", \"messageText\": \"Available\"}, {\"statusId\": \"Away\", \"messageText\": \"Away\"}]}\n\n"
+	suite.Require().Eventually(func() bool { return len(catalog.All()) == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	allowed, err := catalog.AllowedFor("agent")
+	suite.Require().Nil(err)
+	suite.Require().Len(allowed, 1)
+	suite.Assert().Equal("Available", allowed[0].ID)
+
+	suite.Require().Nil(catalog.Close())
+	suite.Assert().Equal(1, server.Count(http.MethodDelete, "/icws/1234/messaging/subscriptions/status/status-messages"))
+}
@@ -1,6 +1,7 @@
 package icws
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/gildas/go-errors"
@@ -27,15 +28,15 @@ func (subscription StatusMessageMessage) GetType() string {
 // Subscribe subscribe a Session to this type of messages
 //
 // implements Subscriber
-func (subscription StatusMessageMessage) Subscribe(session *Session, payload interface{}) error {
-	return session.sendPut("/messaging/subscriptions/status/status-messages", payload, nil)
+func (subscription StatusMessageMessage) Subscribe(context context.Context, session *Session, payload interface{}) error {
+	return session.sendPutContext(context, "/messaging/subscriptions/status/status-messages", payload, nil)
 }
 
 // Subscribe subscribe a Session to this type of messages
 //
 // implements Unsubscriber
-func (subscription StatusMessageMessage) Unsubscribe(session *Session) error {
-	return session.sendDelete("/messaging/subscriptions/status/status-messages")
+func (subscription StatusMessageMessage) Unsubscribe(context context.Context, session *Session) error {
+	return session.sendDeleteContext(context, "/messaging/subscriptions/status/status-messages")
 }
 
 // MarshalJSON marshals into JSON
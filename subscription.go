@@ -1,25 +1,148 @@
 package icws
 
-import "github.com/gildas/go-core"
+import (
+	"context"
 
+	"github.com/gildas/go-core"
+)
+
+// Subscription describes a type of messages a Session can subscribe to
+//
+// The context cancels the subscription requests sent to PureConnect.
 type Subscription interface {
 	core.TypeCarrier
-	Subscribe(session *Session, payload interface{}) error
-	Unsubscribe(session *Session) error
+	Subscribe(context context.Context, session *Session, payload interface{}) error
+	Unsubscribe(context context.Context, session *Session) error
+}
+
+// IdentifiedSubscription is a Subscription that can exist several times per Session
+//
+// Each instance is identified by its Subscription ID (e.g.: queue subscriptions)
+type IdentifiedSubscription interface {
+	Subscription
+	GetSubscriptionID() string
+}
+
+// SubscriptionHandle identifies a Subscription of a Session
+type SubscriptionHandle struct {
+	Key        string
+	Subscriber Subscription
+	session    *Session
 }
 
+// Subscribe subscribes the Session to the messages of the given Subscription
+//
+// Subscribing again with the same Subscription (same type and, for an IdentifiedSubscription,
+// same Subscription ID) replaces the payload of the existing subscription.
+//
+// Use SubscribeWithHandle to get a handle on the subscription.
 func (session *Session) Subscribe(subscriber Subscription, payload interface{}) error {
-	err := subscriber.Subscribe(session, payload)
-	if err == nil {
-		session.Subscriptions[subscriber.GetType()] = subscriber
-	}
+	return session.SubscribeContext(session.Context, subscriber, payload)
+}
+
+// SubscribeContext subscribes the Session to the messages of the given Subscription
+//
+// The context cancels the subscription request, the subscription is not recorded if it is canceled.
+func (session *Session) SubscribeContext(context context.Context, subscriber Subscription, payload interface{}) error {
+	_, err := session.SubscribeWithHandleContext(context, subscriber, payload)
 	return err
 }
 
+// SubscribeWithHandle subscribes the Session to the messages of the given Subscription
+//
+// The handle allows to update the payload or to unsubscribe from this Subscription only.
+func (session *Session) SubscribeWithHandle(subscriber Subscription, payload interface{}) (*SubscriptionHandle, error) {
+	return session.SubscribeWithHandleContext(session.Context, subscriber, payload)
+}
+
+// SubscribeWithHandleContext subscribes the Session to the messages of the given Subscription
+//
+// The context cancels the subscription request, the subscription is not recorded if it is canceled.
+func (session *Session) SubscribeWithHandleContext(context context.Context, subscriber Subscription, payload interface{}) (*SubscriptionHandle, error) {
+	if err := subscriber.Subscribe(context, session, payload); err != nil {
+		return nil, err
+	}
+	key := subscriptionKey(subscriber)
+	session.mutex.Lock()
+	session.Subscriptions[key] = subscriber
+	session.subscriptionPayloads[key] = payload
+	session.mutex.Unlock()
+	return &SubscriptionHandle{Key: key, Subscriber: subscriber, session: session}, nil
+}
+
+// Unsubscribe unsubscribes the Session from the messages of the given Subscription
 func (session *Session) Unsubscribe(unsubscriber Subscription) error {
-	err := unsubscriber.Unsubscribe(session)
-	if err == nil {
-		delete(session.Subscriptions, unsubscriber.GetType())
+	return session.UnsubscribeContext(session.Context, unsubscriber)
+}
+
+// UnsubscribeContext unsubscribes the Session from the messages of the given Subscription
+//
+// The context cancels the unsubscription request, the subscription is kept if it is canceled.
+func (session *Session) UnsubscribeContext(context context.Context, unsubscriber Subscription) error {
+	if err := unsubscriber.Unsubscribe(context, session); err != nil {
+		return err
 	}
-	return err
+	key := subscriptionKey(unsubscriber)
+	session.mutex.Lock()
+	delete(session.Subscriptions, key)
+	delete(session.subscriptionPayloads, key)
+	session.mutex.Unlock()
+	return nil
+}
+
+// GetSubscription gives the handle of the Subscription with the given key, if any
+func (session *Session) GetSubscription(key string) (*SubscriptionHandle, bool) {
+	session.mutex.RLock()
+	defer session.mutex.RUnlock()
+	subscriber, found := session.Subscriptions[key]
+	if !found {
+		return nil, false
+	}
+	return &SubscriptionHandle{Key: key, Subscriber: subscriber, session: session}, true
+}
+
+// Payload gives the payload the Subscription was last sent with
+func (handle SubscriptionHandle) Payload() interface{} {
+	handle.session.mutex.RLock()
+	defer handle.session.mutex.RUnlock()
+	return handle.session.subscriptionPayloads[handle.Key]
+}
+
+// Update sends a new payload for the Subscription
+func (handle SubscriptionHandle) Update(payload interface{}) error {
+	return handle.UpdateContext(handle.session.Context, payload)
+}
+
+// UpdateContext sends a new payload for the Subscription
+//
+// The context cancels the request
+func (handle SubscriptionHandle) UpdateContext(context context.Context, payload interface{}) error {
+	return handle.session.SubscribeContext(context, handle.Subscriber, payload)
+}
+
+// Unsubscribe unsubscribes the Session from this Subscription only
+func (handle SubscriptionHandle) Unsubscribe() error {
+	return handle.UnsubscribeContext(handle.session.Context)
+}
+
+// UnsubscribeContext unsubscribes the Session from this Subscription only
+//
+// The context cancels the request
+func (handle SubscriptionHandle) UnsubscribeContext(context context.Context) error {
+	return handle.session.UnsubscribeContext(context, handle.Subscriber)
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (handle SubscriptionHandle) String() string {
+	return handle.Key
+}
+
+// subscriptionKey gives the key of a Subscription in Session.Subscriptions
+func subscriptionKey(subscriber Subscription) string {
+	if identified, ok := subscriber.(IdentifiedSubscription); ok && len(identified.GetSubscriptionID()) > 0 {
+		return subscriber.GetType() + "/" + identified.GetSubscriptionID()
+	}
+	return subscriber.GetType()
 }
@@ -0,0 +1,37 @@
+package icws_test
+
+import (
+	"net/http"
+
+	"github.com/gildas/go-icws"
+)
+
+func (suite *SessionSuite) TestCanSubscribeSeveralTimesToTheSameType() {
+	session, server := suite.NewConnectedSession()
+
+	mine, err := session.SubscribeQueues("mine", []icws.QueueID{icws.NewUserQueue("agent")})
+	suite.Require().Nil(err)
+	sales, err := session.SubscribeQueues("sales", []icws.QueueID{icws.NewWorkgroupQueue("Sales")})
+	suite.Require().Nil(err)
+	suite.Assert().NotEqual(mine.Key, sales.Key)
+	suite.Assert().Len(session.Subscriptions, 3, "The user status subscription and both queue subscriptions should be registered")
+
+	err = sales.Update(icws.QueueSubscription{Queues: []icws.QueueID{icws.NewWorkgroupQueue("Sales"), icws.NewWorkgroupQueue("Support")}})
+	suite.Require().Nil(err)
+	suite.Assert().Equal(2, server.Count(http.MethodPut, "/icws/1234/messaging/subscriptions/queues/sales"))
+	payload, ok := sales.Payload().(icws.QueueSubscription)
+	suite.Require().True(ok)
+	suite.Assert().Len(payload.Queues, 2)
+
+	suite.Require().Nil(mine.Unsubscribe())
+	suite.Assert().Equal(1, server.Count(http.MethodDelete, "/icws/1234/messaging/subscriptions/queues/mine"))
+	_, found := session.GetSubscription(mine.Key)
+	suite.Assert().False(found)
+	_, found = session.GetSubscription(sales.Key)
+	suite.Assert().True(found)
+
+	suite.Require().Nil(session.Disconnect())
+	suite.Assert().Equal(1, server.Count(http.MethodDelete, "/icws/1234/messaging/subscriptions/queues/sales"))
+	suite.Assert().Equal(1, server.Count(http.MethodDelete, "/icws/1234/messaging/subscriptions/status/user-statuses"))
+	suite.Assert().Empty(session.Subscriptions)
+}
@@ -0,0 +1,183 @@
+package icws
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"syscall"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-request"
+)
+
+// SwitchoverFailed is used when a Session cannot recover from a PureConnect switchover
+var SwitchoverFailed = errors.NewSentinel(http.StatusServiceUnavailable, "error.icws.switchover.failed", "Failed to recover from switchover")
+
+// noRecoveryKey marks the contexts of requests that must not recover from switchovers
+type noRecoveryKey struct{}
+
+// withoutRecovery returns a context whose requests do not recover from switchovers
+//
+// connect and reconnect use it: a recovery started from there would wait for the connection or the recovery in progress, that is, for itself.
+func withoutRecovery(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, noRecoveryKey{}, true)
+}
+
+// canRecover tells if requests sent with the context may recover from switchovers
+func canRecover(ctx context.Context) bool {
+	if ctx == nil {
+		return true
+	}
+	noRecovery, _ := ctx.Value(noRecoveryKey{}).(bool)
+	return !noRecovery
+}
+
+// recover reconnects the Session after it lost the PureConnect session sessionID
+//
+// If alternates are given, the Session connects to them.
+// Concurrent callers that lost the same PureConnect session share the same recovery.
+//
+// returns true if the Session is now connected to another PureConnect session
+func (session *Session) recover(sessionID string, alternates []string) (bool, error) {
+	log := session.Logger.Child(nil, "recover")
+
+	session.mutex.Lock()
+	if recovering := session.recovering; recovering != nil {
+		session.mutex.Unlock()
+		if recovering.sessionID != sessionID {
+			return false, nil // This request was sent while recovering, let the recovery deal with it
+		}
+		log.Debugf("Session %s is already recovering, waiting", sessionID)
+		if err := recovering.wait(); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	if session.ID != sessionID {
+		// Another caller already recovered the Session
+		recovered := session.isConnected()
+		session.mutex.Unlock()
+		return recovered, nil
+	}
+	recovering := newFlight(sessionID)
+	session.recovering = recovering
+	if session.APIRoot != nil {
+		if servers := alternateServers(session.APIRoot, alternates); len(servers) > 0 {
+			log.Infof("Switching over to %v", alternates)
+			session.Servers = servers
+		}
+	}
+	session.mutex.Unlock()
+
+	err := session.reconnect()
+
+	session.mutex.Lock()
+	session.recovering = nil
+	session.mutex.Unlock()
+	recovering.complete(err)
+	if err != nil {
+		return false, err
+	}
+	log.Infof("Recovered session %s as %s", sessionID, session.GetID())
+	return true, nil
+}
+
+// reconnect connects the Session again after its PureConnect session was lost
+//
+// The subscriptions and the station settings of the Session are restored once connected.
+// The subscriptions that could not be restored are kept, so the next recovery can restore them.
+func (session *Session) reconnect() error {
+	log := session.Logger.Child(nil, "reconnect")
+
+	// The old PureConnect session is gone, there is nothing to disconnect from
+	session.eventStream.stop()
+
+	session.mutex.Lock()
+	subscriptions := session.Subscriptions
+	payloads := session.subscriptionPayloads
+	stationSettings := session.StationSettings
+	session.Status = DisconnectedStatus
+	session.ID = ""
+	session.Token = ""
+	session.Cookies = nil
+	session.Subscriptions = map[string]Subscription{}
+	session.subscriptionPayloads = map[string]interface{}{}
+	session.mutex.Unlock()
+
+	// keep puts back a subscription that was not restored, unless it was subscribed again meanwhile
+	keep := func(key string) {
+		session.mutex.Lock()
+		defer session.mutex.Unlock()
+		if _, found := session.Subscriptions[key]; !found {
+			session.Subscriptions[key] = subscriptions[key]
+			session.subscriptionPayloads[key] = payloads[key]
+		}
+	}
+
+	if err := session.Connect(); err != nil {
+		log.Errorf("Failed to reconnect", err)
+		for key := range subscriptions {
+			keep(key)
+		}
+		return err
+	}
+	var errs errors.MultiError
+	for key, subscription := range subscriptions {
+		if key == (UserStatusMessage{}).GetType() {
+			continue // Connect subscribed to the user statuses already
+		}
+		if err := session.SubscribeContext(withoutRecovery(session.Context), subscription, payloads[key]); err != nil {
+			log.Errorf("Failed to restore subscription %s", key, err)
+			keep(key)
+			errs.Append(err)
+			continue
+		}
+		log.Debugf("Restored subscription %s", key)
+	}
+	if stationSettings != nil {
+		if err := session.connectStation(withoutRecovery(session.Context), stationSettings); err != nil {
+			log.Errorf("Failed to restore station %s", stationSettings, err)
+			errs.Append(err)
+		} else {
+			log.Debugf("Restored station %s", stationSettings)
+		}
+	}
+	return errs.AsError()
+}
+
+// mustRecover tells if the failed request means the Session lost its PureConnect session
+//
+// A switchover is certain when PureConnect sends alternate hosts.
+// Otherwise, a 503 or a broken connection might only concern this request,
+// so the Session recovers only if its connection check fails as well.
+func (session *Session) mustRecover(context context.Context, err error, response *request.Content) bool {
+	if !isSwitchoverError(err) {
+		return false
+	}
+	if errors.Is(err, errors.HTTPServiceUnavailable) && len(getAlternateHosts(response)) > 0 {
+		return true
+	}
+	_, err = session.sendRequest(context, http.MethodGet, "/connection", nil, nil, nil, nil)
+	return err != nil && (context == nil || context.Err() == nil)
+}
+
+// isSwitchoverError tells if the error means the PureConnect Server is switching over
+func isSwitchoverError(err error) bool {
+	return errors.Is(err, errors.HTTPServiceUnavailable) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isIdempotent tells if requests with the given HTTP method can be replayed safely
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
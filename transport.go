@@ -0,0 +1,49 @@
+package icws
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// newTransport creates the HTTP transport of a Session from its options
+func newTransport(options SessionOptions) *http.Transport {
+	var transport *http.Transport
+	if options.Transport != nil {
+		transport = options.Transport.Clone()
+	} else {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	if options.TLSConfig != nil {
+		transport.TLSClientConfig = options.TLSConfig.Clone()
+	}
+	if options.InsecureSkipVerify {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.InsecureSkipVerify = true // #nosec G402 -- explicitly requested for labs
+	}
+	if options.Proxy != nil {
+		transport.Proxy = http.ProxyURL(options.Proxy)
+	}
+	return transport
+}
+
+// newStreamTransport creates the HTTP transport used to receive the Server-Sent Events
+//
+// RequestTimeout applies to the response headers, so the transport is a clone of the Session's one
+// only when RequestTimeout is set.
+func newStreamTransport(transport *http.Transport, options SessionOptions) *http.Transport {
+	if options.RequestTimeout <= 0 {
+		return transport
+	}
+	transport = transport.Clone()
+	transport.ResponseHeaderTimeout = options.RequestTimeout
+	return transport
+}
+
+// streamClient gives the HTTP client used to receive the Server-Sent Events
+//
+// The client has no overall timeout as the response body is read for as long as the Session is connected.
+func (session *Session) streamClient() *http.Client {
+	return &http.Client{Transport: session.streamTransport}
+}
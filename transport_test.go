@@ -0,0 +1,119 @@
+package icws_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gildas/go-icws"
+)
+
+func (suite *SessionSuite) TestShouldFailConnectingWithUnknownCertificateAuthority() {
+	server := NewFakeTLSServer()
+	defer server.Close()
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:               []*url.URL{server.URL("127.0.0.1")},
+		UserID:                "agent",
+		Password:              "s3cr3t",
+		Application:           "test",
+		MaxSwitchoverAttempts: 1,
+		RequestAttempts:       1,
+	})
+	err := session.Connect()
+	suite.Require().NotNil(err, "Connect should fail with a certificate signed by an unknown authority")
+	suite.Assert().Equal(0, server.LoginCount())
+}
+
+func (suite *SessionSuite) TestCanConnectWithCustomCertificateAuthority() {
+	server := NewFakeTLSServer()
+	defer server.Close()
+	authorities := x509.NewCertPool()
+	authorities.AddCert(server.Certificate())
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:     []*url.URL{server.URL("127.0.0.1")},
+		UserID:      "agent",
+		Password:    "s3cr3t",
+		Application: "test",
+		TLSConfig:   &tls.Config{RootCAs: authorities},
+	})
+	suite.Require().Nil(session.Connect())
+	defer session.Disconnect()
+	events := session.Events()
+
+	version, err := session.GetVersion()
+	suite.Require().Nil(err)
+	suite.Assert().Equal(20, version.Major)
+
+	server.StreamEvents <- "data: {\"__type\": \"urn:inin.com:status:userStatusMessage\", \"isDelta\": true, \"userStatusList\": []}\n\n"
+	select {
+	case event := <-events:
+		suite.Assert().IsType(&icws.UserStatusMessage{}, event.Message, "The Server-Sent Events should use the TLS configuration too")
+	case <-time.After(5 * time.Second):
+		suite.FailNow("Timeout while waiting for an event")
+	}
+}
+
+func (suite *SessionSuite) TestCanConnectWithInsecureSkipVerify() {
+	server := NewFakeTLSServer()
+	defer server.Close()
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:            []*url.URL{server.URL("127.0.0.1")},
+		UserID:             "agent",
+		Password:           "s3cr3t",
+		Application:        "test",
+		InsecureSkipVerify: true,
+	})
+	suite.Require().Nil(session.Connect())
+	suite.Assert().True(session.IsConnected())
+	suite.Require().Nil(session.Disconnect())
+}
+
+func (suite *SessionSuite) TestCanConnectThroughProxy() {
+	proxy := NewFakeServer()
+	defer proxy.Close()
+	serverURL, _ := url.Parse("http://icws.invalid:8019")
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:     []*url.URL{serverURL},
+		UserID:      "agent",
+		Password:    "s3cr3t",
+		Application: "test",
+		Proxy:       proxy.URL("127.0.0.1"),
+	})
+	suite.Require().Nil(session.Connect())
+	defer session.Disconnect()
+	suite.Assert().Equal(1, proxy.LoginCount(), "The login should go through the proxy")
+
+	_, err := session.GetVersion()
+	suite.Require().Nil(err)
+}
+
+func (suite *SessionSuite) TestShouldTimeoutSlowRequests() {
+	server := NewFakeServer()
+	defer server.Close()
+	server.Handlers["GET /icws/1234/configuration/users/agent"] = func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	session := icws.NewSession(icws.SessionOptions{
+		Servers:         []*url.URL{server.URL("127.0.0.1")},
+		UserID:          "agent",
+		Password:        "s3cr3t",
+		Application:     "test",
+		RequestTimeout:  100 * time.Millisecond,
+		RequestAttempts: 1,
+	})
+	suite.Require().Nil(session.Connect())
+	defer session.Disconnect()
+
+	start := time.Now()
+	_, err := icws.GetConfiguration[icws.UserConfiguration](session, "agent", icws.QueryOptions{})
+	suite.Require().NotNil(err, "The request should time out")
+	suite.Assert().Less(time.Since(start), 450*time.Millisecond)
+}
@@ -1,8 +1,6 @@
 package icws
 
-import (
-	"net/http"
-)
+import "context"
 
 // User describes a PureConnect User
 type User struct {
@@ -12,14 +10,8 @@ type User struct {
 	License     LicenseProperties
 }
 
-type userConfiguration struct {
-	ID          string `json:"id"`
-	DisplayName string `json:"displayName,omitempty"`
-	SelfUri     string `json:"uri"`
-}
-
 type userRecord struct {
-	UserConfiguration         userConfiguration `json:"configurationId"`
+	UserConfiguration         ConfigurationID   `json:"configurationId"`
 	NTDomainUser              string            `json:"ntDomainUser"`
 	MWIEnabled                bool              `json:"mwiEnabled"`
 	MWIMode                   int               `json:"mwiMode"`
@@ -39,59 +31,64 @@ func (user User) GetID() string {
 
 // GetUsers retrieves a list of Users
 func (session *Session) GetUsers() ([]User, error) {
+	return session.GetUsersContext(session.Context)
+}
+
+// GetUsersContext retrieves a list of Users
+//
+// The context cancels the request
+func (session *Session) GetUsersContext(context context.Context) ([]User, error) {
 	data := struct {
 		Items []userRecord `json:"items"`
 	}{}
-	err := session.sendGet("/configuration/users", &data)
+	err := session.sendGetContext(context, "/configuration/users", &data)
 	users := make([]User, len(data.Items))
 	for i := 0; i < len(data.Items); i++ {
 		users[i] = User{
 			ID:          data.Items[i].UserConfiguration.ID,
 			DisplayName: data.Items[i].UserConfiguration.DisplayName,
-			SelfUri:     data.Items[i].UserConfiguration.SelfUri,
+			SelfUri:     data.Items[i].UserConfiguration.URI,
 		}
 	}
 	return users, err
 }
 
-// GetUsers retrieves a list of Users
+// GetUsersWithOptions retrieves a list of Users
 func (session *Session) GetUsersWithOptions(options QueryOptions) ([]User, error) {
-	// If there is a select option, users are sent back 200 at a time
-	// See: https://help.genesys.com/developer/cic/docs/icws/webhelp/icws/(sessionId)/configuration/users/index.htm#get
-	data := struct {
-		Items []userRecord `json:"items"`
-	}{}
-	headers := map[string]string{}
-	users := []User{}
-	for userRange := NewRange("items"); !userRange.IsAtEnd(); {
-		userRange.ToMap(headers)
-		response, err := session.send(
-			http.MethodGet,
-			"/configuration/users",
-			headers,
-			options.AsQueryParameters(),
-			nil,
-			&data,
-		)
-		if err != nil {
-			return []User{}, err
-		}
+	return session.GetUsersWithOptionsContext(session.Context, options)
+}
 
-		for _, item := range data.Items {
-			user := User{
-				ID:          item.UserConfiguration.ID,
-				DisplayName: item.UserConfiguration.DisplayName,
-				SelfUri:     item.UserConfiguration.SelfUri,
-				License:     item.LicenseProperties,
-			}
-			users = append(users, user)
-		}
-		userRange = GetRangeFromHeader(response.Headers)
-		session.Logger.Tracef("New Range: %#+v", userRange)
+// GetUsersWithOptionsContext retrieves a list of Users
+//
+// The context cancels the requests of all the pages
+func (session *Session) GetUsersWithOptionsContext(context context.Context, options QueryOptions) ([]User, error) {
+	users := []User{}
+	err := session.ForEachUser(PageOptions{Context: context, Query: options}, func(user User) error {
+		users = append(users, user)
+		return nil
+	})
+	if err != nil {
+		return []User{}, err
 	}
 	return users, nil
 }
 
+// ForEachUser calls the callback with each User, one page at a time
+//
+// The walk stops at the first error returned by the callback
+func (session *Session) ForEachUser(options PageOptions, callback func(user User) error) error {
+	// If there is a select option, users are sent back 200 at a time
+	// See: https://help.genesys.com/developer/cic/docs/icws/webhelp/icws/(sessionId)/configuration/users/index.htm#get
+	return Paginate[userRecord](session, "/configuration/users", options).ForEach(func(item userRecord) error {
+		return callback(User{
+			ID:          item.UserConfiguration.ID,
+			DisplayName: item.UserConfiguration.DisplayName,
+			SelfUri:     item.UserConfiguration.URI,
+			License:     item.LicenseProperties,
+		})
+	})
+}
+
 // String gets a text representation
 //
 // implements fmt.Stringer
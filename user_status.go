@@ -1,7 +1,10 @@
 package icws
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -20,6 +23,120 @@ type UserStatus struct {
 	Stations         []string  `json:"stations"`
 }
 
+// UserStatusOptions describes the options when setting a User Status
+type UserStatusOptions struct {
+	// ForwardNumber is the number calls are forwarded to
+	ForwardNumber string
+	// Until tells when the status ends, only allowed if the StatusMessage can have a date or a time
+	Until time.Time
+	// UntilHasDate tells if the date of Until is relevant, the StatusMessage must allow dates
+	UntilHasDate bool
+	// UntilHasTime tells if the time of Until is relevant, the StatusMessage must allow times
+	UntilHasTime bool
+	// Notes are free text notes attached to the status
+	Notes []string
+}
+
+type userStatusUntil struct {
+	UntilDateTime Time `json:"untilDateTime"`
+	HasDate       bool `json:"hasDate"`
+	HasTime       bool `json:"hasTime"`
+}
+
+type userStatusUpdate struct {
+	StatusID      string           `json:"statusId"`
+	ForwardNumber string           `json:"forwardNumber,omitempty"`
+	Until         *userStatusUntil `json:"until,omitempty"`
+	Notes         []string         `json:"notes,omitempty"`
+}
+
+// SetUserStatus sets the status of a User
+//
+// If options.Until is set, the StatusMessage of statusID is retrieved
+// to verify it allows a date and/or a time.
+func (session *Session) SetUserStatus(userID, statusID string, options UserStatusOptions) error {
+	return session.SetUserStatusContext(session.Context, userID, statusID, options)
+}
+
+// SetUserStatusContext sets the status of a User
+//
+// The context cancels the requests
+func (session *Session) SetUserStatusContext(context context.Context, userID, statusID string, options UserStatusOptions) error {
+	if len(userID) == 0 {
+		return errors.ArgumentMissing.With("userID")
+	}
+	if len(statusID) == 0 {
+		return errors.ArgumentMissing.With("statusID")
+	}
+	update := userStatusUpdate{
+		StatusID:      statusID,
+		ForwardNumber: options.ForwardNumber,
+		Notes:         options.Notes,
+	}
+	if !options.Until.IsZero() {
+		if !options.UntilHasDate && !options.UntilHasTime {
+			return errors.ArgumentInvalid.With("until", "neither date nor time")
+		}
+		statusMessage, err := session.GetStatusMessageContext(context, statusID)
+		if err != nil {
+			return err
+		}
+		if options.UntilHasDate && !statusMessage.CanHaveDate {
+			return errors.ArgumentInvalid.With("until", "status "+statusID+" cannot have a date")
+		}
+		if options.UntilHasTime && !statusMessage.CanHaveTime {
+			return errors.ArgumentInvalid.With("until", "status "+statusID+" cannot have a time")
+		}
+		update.Until = &userStatusUntil{
+			UntilDateTime: Time(options.Until.UTC()),
+			HasDate:       options.UntilHasDate,
+			HasTime:       options.UntilHasTime,
+		}
+	}
+	return session.sendPutContext(context, "/status/user-statuses/"+url.PathEscape(userID), update, nil)
+}
+
+// GetUserStatus retrieves the status of a User
+func (session *Session) GetUserStatus(userID string) (*UserStatus, error) {
+	return session.GetUserStatusContext(session.Context, userID)
+}
+
+// GetUserStatusContext retrieves the status of a User
+//
+// The context cancels the request
+func (session *Session) GetUserStatusContext(context context.Context, userID string) (*UserStatus, error) {
+	if len(userID) == 0 {
+		return nil, errors.ArgumentMissing.With("userID")
+	}
+	status := UserStatus{}
+	if err := session.sendGetContext(context, "/status/user-statuses/"+url.PathEscape(userID), &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// GetUserStatuses retrieves the statuses of the given Users
+func (session *Session) GetUserStatuses(userIDs ...string) ([]UserStatus, error) {
+	return session.GetUserStatusesContext(session.Context, userIDs...)
+}
+
+// GetUserStatusesContext retrieves the statuses of the given Users
+//
+// The context cancels the request
+func (session *Session) GetUserStatusesContext(context context.Context, userIDs ...string) ([]UserStatus, error) {
+	if len(userIDs) == 0 {
+		return []UserStatus{}, nil
+	}
+	results := struct {
+		UserStatuses []UserStatus `json:"userStatusList"`
+	}{}
+	_, err := session.sendContext(context, http.MethodGet, "/status/user-statuses", nil, map[string]string{"userIds": strings.Join(userIDs, ",")}, nil, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results.UserStatuses, nil
+}
+
 // String gets a text representation
 //
 // implements fmt.Stringer
@@ -1,7 +1,9 @@
 package icws
 
 import (
+	"context"
 	"encoding/json"
+	"sort"
 	"strings"
 
 	"github.com/gildas/go-errors"
@@ -32,15 +34,120 @@ func (message UserStatusMessage) GetType() string {
 // Subscribe subscribe a Session to this type of messages
 //
 // implements Subscriber
-func (message UserStatusMessage) Subscribe(session *Session, payload interface{}) error {
-	return session.sendPut("/messaging/subscriptions/status/user-statuses", payload, nil)
+func (message UserStatusMessage) Subscribe(context context.Context, session *Session, payload interface{}) error {
+	return session.sendPutContext(context, "/messaging/subscriptions/status/user-statuses", payload, nil)
 }
 
 // Subscribe subscribe a Session to this type of messages
 //
 // implements Unsubscriber
-func (message UserStatusMessage) Unsubscribe(session *Session) error {
-	return session.sendDelete("/messaging/subscriptions/status/user-statuses")
+func (message UserStatusMessage) Unsubscribe(context context.Context, session *Session) error {
+	return session.sendDeleteContext(context, "/messaging/subscriptions/status/user-statuses")
+}
+
+// WatchUserStatuses adds Users to the user status subscription of the Session
+//
+// PureConnect allows only one user status subscription per session, it is shared
+// by the Session's own User and all the watchers. A User stays in the subscription
+// until it has been unwatched as many times as it was watched.
+func (session *Session) WatchUserStatuses(userIDs ...string) error {
+	return session.WatchUserStatusesContext(session.Context, userIDs...)
+}
+
+// WatchUserStatusesContext adds Users to the user status subscription of the Session
+//
+// The context cancels the request
+func (session *Session) WatchUserStatusesContext(context context.Context, userIDs ...string) error {
+	session.mutex.Lock()
+	for _, userID := range userIDs {
+		session.watchedUsers[userID]++
+	}
+	subscription, connected := session.userStatusSubscription()
+	session.mutex.Unlock()
+	if err := session.updateUserStatusSubscription(context, subscription, connected); err != nil {
+		session.mutex.Lock()
+		session.unwatchUsers(userIDs)
+		session.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// UnwatchUserStatuses removes Users from the user status subscription of the Session
+func (session *Session) UnwatchUserStatuses(userIDs ...string) error {
+	return session.UnwatchUserStatusesContext(session.Context, userIDs...)
+}
+
+// UnwatchUserStatusesContext removes Users from the user status subscription of the Session
+//
+// The context cancels the request
+func (session *Session) UnwatchUserStatusesContext(context context.Context, userIDs ...string) error {
+	session.mutex.Lock()
+	removed := session.unwatchUsers(userIDs)
+	subscription, connected := session.userStatusSubscription()
+	session.mutex.Unlock()
+	if err := session.updateUserStatusSubscription(context, subscription, connected); err != nil {
+		session.mutex.Lock()
+		for _, userID := range removed {
+			session.watchedUsers[userID]++
+		}
+		session.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// unwatchUsers decrements the watchers of the given Users
+//
+// The caller must hold the Session's mutex.
+// returns the Users that were actually watched, so they can be watched again if needed
+func (session *Session) unwatchUsers(userIDs []string) (removed []string) {
+	for _, userID := range userIDs {
+		count, found := session.watchedUsers[userID]
+		if !found {
+			continue
+		}
+		if count > 1 {
+			session.watchedUsers[userID] = count - 1
+		} else {
+			delete(session.watchedUsers, userID)
+		}
+		removed = append(removed, userID)
+	}
+	return removed
+}
+
+// subscribeUserStatuses subscribes to the statuses of the Session's User and of the watched Users
+func (session *Session) subscribeUserStatuses(context context.Context) error {
+	session.mutex.RLock()
+	subscription, connected := session.userStatusSubscription()
+	session.mutex.RUnlock()
+	return session.updateUserStatusSubscription(context, subscription, connected)
+}
+
+// userStatusSubscription gets the subscription of the Session's User and of the watched Users
+//
+// The caller must hold the Session's mutex
+func (session *Session) userStatusSubscription() (subscription UserStatusSubscription, connected bool) {
+	userIDs := make([]string, 0, len(session.watchedUsers)+1)
+	if len(session.User.ID) > 0 && session.watchedUsers[session.User.ID] == 0 {
+		userIDs = append(userIDs, session.User.ID)
+	}
+	for userID := range session.watchedUsers {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+	return UserStatusSubscription{UserIDs: userIDs}, session.isConnected()
+}
+
+// updateUserStatusSubscription sends the user status subscription to PureConnect
+//
+// No lock is held while sending, as the request might recover from a switchover and connect again.
+func (session *Session) updateUserStatusSubscription(context context.Context, subscription UserStatusSubscription, connected bool) error {
+	if !connected {
+		return nil // Connect will subscribe
+	}
+	return session.SubscribeContext(context, UserStatusMessage{}, subscription)
 }
 
 // String gets a text representation
@@ -0,0 +1,240 @@
+package icws
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UserStatusRoster tracks the statuses of a set of Users
+//
+// Users can be added and removed at any time, the user status subscription of the Session
+// is updated in place. The roster applies the UserStatusMessage in the order they are received
+// and reports the changes to its handlers.
+type UserStatusRoster struct {
+	messageFeed[UserStatusMessage]
+	users    map[string]bool
+	statuses map[string]UserStatus
+	handlers []func(UserStatusChange)
+	mutex    sync.RWMutex
+}
+
+// UserStatusChange describes the change of the status of a User
+type UserStatusChange struct {
+	UserID           string
+	From             string // the previous StatusID, empty if IsNew
+	To               string // the current StatusID
+	ChangedAt        time.Time
+	WasOnPhone       bool
+	IsOnPhone        bool
+	OnPhoneChangedAt time.Time
+	WasLoggedIn      bool
+	IsLoggedIn       bool
+	IsNew            bool // true if this is the first status received for the User
+}
+
+// NewUserStatusRoster creates a new UserStatusRoster
+//
+// If session is not nil, the UserStatusMessage it receives are applied to the roster.
+func NewUserStatusRoster(session *Session) *UserStatusRoster {
+	roster := &UserStatusRoster{
+		users:    map[string]bool{},
+		statuses: map[string]UserStatus{},
+	}
+	if session != nil {
+		roster.start(session, func(message UserStatusMessage) { roster.Apply(message) })
+	}
+	return roster
+}
+
+// OnChange registers a handler for the status changes
+//
+// The handlers are called in order, one change at a time, they should return quickly.
+func (roster *UserStatusRoster) OnChange(handler func(UserStatusChange)) {
+	roster.mutex.Lock()
+	defer roster.mutex.Unlock()
+	roster.handlers = append(roster.handlers, handler)
+}
+
+// Add adds Users to the roster
+func (roster *UserStatusRoster) Add(userIDs ...string) error {
+	if roster.session == nil {
+		return roster.AddContext(context.Background(), userIDs...)
+	}
+	return roster.AddContext(roster.session.Context, userIDs...)
+}
+
+// AddContext adds Users to the roster
+//
+// The context cancels the request
+func (roster *UserStatusRoster) AddContext(context context.Context, userIDs ...string) error {
+	added := make([]string, 0, len(userIDs))
+	roster.mutex.Lock()
+	for _, userID := range userIDs {
+		if !roster.users[userID] {
+			roster.users[userID] = true
+			added = append(added, userID)
+		}
+	}
+	roster.mutex.Unlock()
+	if len(added) == 0 || roster.session == nil {
+		return nil
+	}
+	if err := roster.session.WatchUserStatusesContext(context, added...); err != nil {
+		roster.mutex.Lock()
+		for _, userID := range added {
+			delete(roster.users, userID)
+		}
+		roster.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Remove removes Users from the roster
+func (roster *UserStatusRoster) Remove(userIDs ...string) error {
+	if roster.session == nil {
+		return roster.RemoveContext(context.Background(), userIDs...)
+	}
+	return roster.RemoveContext(roster.session.Context, userIDs...)
+}
+
+// RemoveContext removes Users from the roster
+//
+// The context cancels the request
+func (roster *UserStatusRoster) RemoveContext(context context.Context, userIDs ...string) error {
+	removed := make([]string, 0, len(userIDs))
+	roster.mutex.Lock()
+	for _, userID := range userIDs {
+		if roster.users[userID] {
+			delete(roster.users, userID)
+			delete(roster.statuses, userID)
+			removed = append(removed, userID)
+		}
+	}
+	roster.mutex.Unlock()
+	if len(removed) == 0 || roster.session == nil {
+		return nil
+	}
+	return roster.session.UnwatchUserStatusesContext(context, removed...)
+}
+
+// Users gives the IDs of the Users in the roster, sorted
+func (roster *UserStatusRoster) Users() []string {
+	roster.mutex.RLock()
+	defer roster.mutex.RUnlock()
+	userIDs := make([]string, 0, len(roster.users))
+	for userID := range roster.users {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+	return userIDs
+}
+
+// Get gives the status of a User
+func (roster *UserStatusRoster) Get(userID string) (UserStatus, bool) {
+	roster.mutex.RLock()
+	defer roster.mutex.RUnlock()
+	status, found := roster.statuses[userID]
+	return status, found
+}
+
+// Snapshot gives the known statuses of the Users, sorted by User ID
+func (roster *UserStatusRoster) Snapshot() []UserStatus {
+	roster.mutex.RLock()
+	defer roster.mutex.RUnlock()
+	statuses := make([]UserStatus, 0, len(roster.statuses))
+	for _, status := range roster.statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].UserID < statuses[j].UserID })
+	return statuses
+}
+
+// Apply applies a UserStatusMessage and gives the resulting changes
+//
+// Statuses of Users that are not in the roster are ignored.
+// When the message is not a delta, the Users of the roster it does not contain are forgotten.
+func (roster *UserStatusRoster) Apply(message UserStatusMessage) []UserStatusChange {
+	roster.mutex.Lock()
+	changes := []UserStatusChange{}
+	if !message.IsDelta {
+		received := make(map[string]bool, len(message.UserStatuses))
+		for _, status := range message.UserStatuses {
+			received[status.UserID] = true
+		}
+		for userID := range roster.statuses {
+			if !received[userID] {
+				delete(roster.statuses, userID)
+			}
+		}
+	}
+	for _, status := range message.UserStatuses {
+		if !roster.users[status.UserID] {
+			continue
+		}
+		previous, found := roster.statuses[status.UserID]
+		roster.statuses[status.UserID] = status
+		if found && previous.StatusID == status.StatusID && previous.IsOnPhone == status.IsOnPhone && previous.IsLoggedIn == status.IsLoggedIn {
+			continue
+		}
+		changes = append(changes, UserStatusChange{
+			UserID:           status.UserID,
+			From:             previous.StatusID,
+			To:               status.StatusID,
+			ChangedAt:        status.ChangedAt,
+			WasOnPhone:       previous.IsOnPhone,
+			IsOnPhone:        status.IsOnPhone,
+			OnPhoneChangedAt: status.OnPhoneChangedAt,
+			WasLoggedIn:      previous.IsLoggedIn,
+			IsLoggedIn:       status.IsLoggedIn,
+			IsNew:            !found,
+		})
+	}
+	handlers := make([]func(UserStatusChange), len(roster.handlers))
+	copy(handlers, roster.handlers)
+	roster.mutex.Unlock()
+
+	for _, change := range changes {
+		for _, handler := range handlers {
+			handler(change)
+		}
+	}
+	return changes
+}
+
+// Close removes all Users from the roster and stops feeding it
+func (roster *UserStatusRoster) Close() error {
+	if roster.session == nil {
+		return nil
+	}
+	err := roster.Remove(roster.Users()...)
+	roster.stop()
+	return err
+}
+
+// String gets a text representation
+//
+// implements fmt.Stringer
+func (change UserStatusChange) String() string {
+	sb := strings.Builder{}
+	sb.WriteString(change.UserID)
+	sb.WriteString(": ")
+	if change.IsNew {
+		sb.WriteString(change.To)
+	} else {
+		sb.WriteString(change.From)
+		sb.WriteString(" -> ")
+		sb.WriteString(change.To)
+	}
+	if change.IsOnPhone != change.WasOnPhone {
+		if change.IsOnPhone {
+			sb.WriteString(", on the phone")
+		} else {
+			sb.WriteString(", off the phone")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,93 @@
+package icws_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gildas/go-icws"
+)
+
+func (suite *SessionSuite) TestCanApplyUserStatusChanges() {
+	roster := icws.NewUserStatusRoster(nil)
+	suite.Require().Nil(roster.Add("agent", "supervisor"))
+
+	changes := roster.Apply(icws.UserStatusMessage{
+		IsDelta: false,
+		UserStatuses: []icws.UserStatus{
+			{UserID: "agent", StatusID: "Available", IsLoggedIn: true},
+			{UserID: "supervisor", StatusID: "Away", IsLoggedIn: true},
+			{UserID: "stranger", StatusID: "Available"},
+		},
+	})
+	suite.Require().Len(changes, 2)
+	suite.Assert().True(changes[0].IsNew)
+	suite.Assert().Len(roster.Snapshot(), 2)
+
+	changedAt := time.Date(2021, 7, 6, 19, 32, 29, 0, time.UTC)
+	changes = roster.Apply(icws.UserStatusMessage{
+		IsDelta: true,
+		UserStatuses: []icws.UserStatus{
+			{UserID: "agent", StatusID: "Available", IsLoggedIn: true, IsOnPhone: true, OnPhoneChangedAt: changedAt},
+			{UserID: "supervisor", StatusID: "Away", IsLoggedIn: true},
+		},
+	})
+	suite.Require().Len(changes, 1)
+	suite.Assert().Equal("agent", changes[0].UserID)
+	suite.Assert().False(changes[0].IsNew)
+	suite.Assert().Equal(changedAt, changes[0].OnPhoneChangedAt)
+	suite.Assert().Equal("agent: Available -> Available, on the phone", changes[0].String())
+
+	suite.Require().Nil(roster.Remove("supervisor"))
+	_, found := roster.Get("supervisor")
+	suite.Assert().False(found)
+	suite.Assert().Equal([]string{"agent"}, roster.Users())
+}
+
+func (suite *SessionSuite) TestCanTrackUserStatuses() {
+	session, server := suite.NewConnectedSession()
+	subscriptionPath := "/icws/1234/messaging/subscriptions/status/user-statuses"
+	suite.Assert().JSONEq(`{"userIds": ["agent"]}`, server.Payload(http.MethodPut, subscriptionPath))
+
+	roster := icws.NewUserStatusRoster(session)
+	defer roster.Close()
+	changes := make(chan icws.UserStatusChange, 10)
+	roster.OnChange(func(change icws.UserStatusChange) { changes <- change })
+
+	suite.Require().Nil(roster.Add("supervisor", "other"))
+	suite.Assert().JSONEq(`{"userIds": ["agent", "other", "supervisor"]}`, server.Payload(http.MethodPut, subscriptionPath))
+
+	server.StreamEvents <- "data: {\"__type\": \"urn:inin.com:status:userStatusMessage\", \"isDelta\": false, \"userStatusList\": [{\"userId\": \"supervisor\", \"statusId\": \"Available\", \"statusChanged\": \"20201029T082018Z\", \"onPhoneChanged\": \"20201029T082018Z\"}]}\n\n"
+	server.StreamEvents <- "data: {\"__type\": \"urn:inin.com:status:userStatusMessage\", \"isDelta\": true, \"userStatusList\": [{\"userId\": \"supervisor\", \"statusId\": \"Away\", \"statusChanged\": \"20201029T090000Z\", \"onPhoneChanged\": \"20201029T082018Z\"}]}\n\n"
+	for _, expected := range []string{"supervisor: Available", "supervisor: Available -> Away"} {
+		select {
+		case change := <-changes:
+			suite.Assert().Equal(expected, change.String())
+		case <-time.After(5 * time.Second):
+			suite.FailNow("Timeout while waiting for a UserStatusChange")
+		}
+	}
+
+	suite.Require().Nil(roster.Remove("other"))
+	suite.Assert().JSONEq(`{"userIds": ["agent", "supervisor"]}`, server.Payload(http.MethodPut, subscriptionPath))
+	suite.Assert().Equal(0, server.Count(http.MethodDelete, subscriptionPath), "The subscription should be updated, not recreated")
+}
+
+func (suite *SessionSuite) TestShouldNotWatchUsersWhenSubscriptionFails() {
+	session, server := suite.NewConnectedSession()
+	subscriptionPath := "/icws/1234/messaging/subscriptions/status/user-statuses"
+	suite.Require().Nil(session.WatchUserStatuses("supervisor"))
+
+	server.mutex.Lock()
+	server.Handlers["PUT "+subscriptionPath] = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	server.mutex.Unlock()
+	suite.Require().NotNil(session.WatchUserStatuses("other"))
+	suite.Require().NotNil(session.UnwatchUserStatuses("supervisor"))
+
+	server.mutex.Lock()
+	delete(server.Handlers, "PUT "+subscriptionPath)
+	server.mutex.Unlock()
+	suite.Require().Nil(session.WatchUserStatuses("manager"))
+	suite.Assert().JSONEq(`{"userIds": ["agent", "manager", "supervisor"]}`, server.Payload(http.MethodPut, subscriptionPath), "Failed updates should not change the watched Users")
+}
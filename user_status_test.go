@@ -0,0 +1,67 @@
+package icws_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-icws"
+)
+
+func (suite *SessionSuite) TestCanSetUserStatus() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["GET /icws/1234/status/status-messages/Gone Home"] = `{"statusId": "Gone Home", "messageText": "Gone Home", "canHaveDate": true, "canHaveTime": true}`
+	})
+
+	suite.Require().Nil(session.SetUserStatus("agent", "Available", icws.UserStatusOptions{}))
+	suite.Assert().JSONEq(`{"statusId": "Available"}`, server.Payload(http.MethodPut, "/icws/1234/status/user-statuses/agent"))
+
+	err := session.SetUserStatus("agent", "Gone Home", icws.UserStatusOptions{
+		ForwardNumber: "+13175551234",
+		Until:         time.Date(2021, 1, 4, 8, 30, 0, 0, time.UTC),
+		UntilHasDate:  true,
+		UntilHasTime:  true,
+		Notes:         []string{"Back on Monday"},
+	})
+	suite.Require().Nil(err)
+	suite.Assert().JSONEq(
+		`{"statusId": "Gone Home", "forwardNumber": "+13175551234", "until": {"untilDateTime": "20210104T083000Z", "hasDate": true, "hasTime": true}, "notes": ["Back on Monday"]}`,
+		server.Payload(http.MethodPut, "/icws/1234/status/user-statuses/agent"),
+	)
+}
+
+func (suite *SessionSuite) TestShouldFailSettingUserStatusWithUnsupportedTime() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["GET /icws/1234/status/status-messages/Vacation"] = `{"statusId": "Vacation", "messageText": "Vacation", "canHaveDate": true, "canHaveTime": false}`
+	})
+
+	err := session.SetUserStatus("agent", "Vacation", icws.UserStatusOptions{
+		Until:        time.Now().Add(48 * time.Hour),
+		UntilHasDate: true,
+		UntilHasTime: true,
+	})
+	suite.Require().NotNil(err)
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentInvalid), "Error should be an ArgumentInvalid, got %s", err)
+	suite.Assert().Equal(0, server.Count(http.MethodPut, "/icws/1234/status/user-statuses/agent"))
+}
+
+func (suite *SessionSuite) TestCanGetUserStatuses() {
+	session, _ := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["GET /icws/1234/status/user-statuses/agent"] = `{"userId": "agent", "statusId": "Available", "loggedIn": true, "statusChanged": "20201029T082018Z", "onPhoneChanged": "20201029T082018Z"}`
+		server.Responses["GET /icws/1234/status/user-statuses"] = `{"userStatusList": [
+			{"userId": "agent", "statusId": "Available", "loggedIn": true, "statusChanged": "20201029T082018Z", "onPhoneChanged": "20201029T082018Z"},
+			{"userId": "supervisor", "statusId": "Away from desk", "loggedIn": true, "statusChanged": "20201029T082018Z", "onPhoneChanged": "20201029T082018Z"}
+		]}`
+	})
+
+	status, err := session.GetUserStatus("agent")
+	suite.Require().Nil(err)
+	suite.Require().NotNil(status)
+	suite.Assert().Equal("Available", status.StatusID)
+	suite.Assert().Equal(time.Date(2020, 10, 29, 8, 20, 18, 0, time.UTC), status.ChangedAt)
+
+	statuses, err := session.GetUserStatuses("agent", "supervisor")
+	suite.Require().Nil(err)
+	suite.Require().Len(statuses, 2)
+	suite.Assert().Equal("Away from desk", statuses[1].StatusID)
+}
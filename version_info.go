@@ -1,6 +1,7 @@
 package icws
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/gildas/go-core"
@@ -21,8 +22,15 @@ type VersionInfo struct {
 
 // GetVersion retrieves the PureConnect version
 func (session *Session) GetVersion() (*VersionInfo, error) {
+	return session.GetVersionContext(session.Context)
+}
+
+// GetVersionContext retrieves the PureConnect version
+//
+// The context cancels the request
+func (session *Session) GetVersionContext(context context.Context) (*VersionInfo, error) {
 	version := VersionInfo{}
-	err := session.sendGet("/connection/version", &version)
+	err := session.sendGetContext(context, "/connection/version", &version)
 	return &version, err
 }
 
@@ -0,0 +1,229 @@
+package icws
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"reflect"
+
+	"github.com/gildas/go-errors"
+)
+
+// WorkgroupActivation tells if a User is activated in a Workgroup
+type WorkgroupActivation struct {
+	Workgroup ConfigurationID `json:"workgroup"`
+	Activated bool            `json:"activated"`
+}
+
+// GetWorkgroupMembers retrieves the members of a Workgroup
+func (session *Session) GetWorkgroupMembers(workgroupID string) ([]User, error) {
+	return session.GetWorkgroupMembersContext(session.Context, workgroupID)
+}
+
+// GetWorkgroupMembersContext retrieves the members of a Workgroup
+//
+// The context cancels the request
+func (session *Session) GetWorkgroupMembersContext(context context.Context, workgroupID string) ([]User, error) {
+	workgroup, err := GetConfigurationContext[WorkgroupConfiguration](context, session, workgroupID, QueryOptions{Fields: []string{"members"}})
+	if err != nil {
+		return nil, err
+	}
+	users := make([]User, len(workgroup.Members))
+	for i, member := range workgroup.Members {
+		users[i] = User{ID: member.ID, DisplayName: member.DisplayName, SelfUri: member.URI}
+	}
+	return users, nil
+}
+
+// AddWorkgroupMembers adds Users to a Workgroup
+//
+// Users that are already members are ignored.
+//
+// PureConnect has no endpoint to add a single member, the members are read, changed, and written back.
+// A change made by someone else between the read and the write is lost.
+func (session *Session) AddWorkgroupMembers(workgroupID string, users ...User) error {
+	return session.AddWorkgroupMembersContext(session.Context, workgroupID, users...)
+}
+
+// AddWorkgroupMembersContext adds Users to a Workgroup
+//
+// The context cancels the requests
+func (session *Session) AddWorkgroupMembersContext(context context.Context, workgroupID string, users ...User) error {
+	return session.updateWorkgroupMembers(context, workgroupID, func(members []ConfigurationID) []ConfigurationID {
+		known := map[string]bool{}
+		for _, member := range members {
+			known[member.ID] = true
+		}
+		for _, userID := range IDList(users) {
+			if !known[userID] {
+				known[userID] = true
+				members = append(members, ConfigurationID{ID: userID})
+			}
+		}
+		return members
+	})
+}
+
+// RemoveWorkgroupMembers removes Users from a Workgroup
+//
+// Like AddWorkgroupMembers, a concurrent change of the members can be lost.
+func (session *Session) RemoveWorkgroupMembers(workgroupID string, users ...User) error {
+	return session.RemoveWorkgroupMembersContext(session.Context, workgroupID, users...)
+}
+
+// RemoveWorkgroupMembersContext removes Users from a Workgroup
+//
+// The context cancels the requests
+func (session *Session) RemoveWorkgroupMembersContext(context context.Context, workgroupID string, users ...User) error {
+	return session.updateWorkgroupMembers(context, workgroupID, func(members []ConfigurationID) []ConfigurationID {
+		removed := map[string]bool{}
+		for _, userID := range IDList(users) {
+			removed[userID] = true
+		}
+		kept := make([]ConfigurationID, 0, len(members))
+		for _, member := range members {
+			if !removed[member.ID] {
+				kept = append(kept, member)
+			}
+		}
+		return kept
+	})
+}
+
+// AssignSkill assigns a Skill to a User, or changes its levels if the User already has it
+//
+// proficiency and desireToUse must be between 0 and 100
+//
+// Like AddWorkgroupMembers, the skills of the User are read, changed, and written back, so a concurrent change of the skills can be lost.
+func (session *Session) AssignSkill(user User, skillID string, proficiency, desireToUse int) error {
+	return session.AssignSkillContext(session.Context, user, skillID, proficiency, desireToUse)
+}
+
+// AssignSkillContext assigns a Skill to a User, or changes its levels if the User already has it
+//
+// The context cancels the requests
+func (session *Session) AssignSkillContext(context context.Context, user User, skillID string, proficiency, desireToUse int) error {
+	if len(skillID) == 0 {
+		return errors.ArgumentMissing.With("skillID")
+	}
+	if proficiency < 0 || proficiency > 100 {
+		return errors.ArgumentInvalid.With("proficiency", proficiency)
+	}
+	if desireToUse < 0 || desireToUse > 100 {
+		return errors.ArgumentInvalid.With("desireToUse", desireToUse)
+	}
+	return session.updateUserSkills(context, user, func(skills []SkillAssignment) []SkillAssignment {
+		for i := range skills {
+			if skills[i].ID.ID == skillID {
+				skills[i].Proficiency = proficiency
+				skills[i].DesireToUse = desireToUse
+				return skills
+			}
+		}
+		return append(skills, SkillAssignment{ID: ConfigurationID{ID: skillID}, Proficiency: proficiency, DesireToUse: desireToUse})
+	})
+}
+
+// UnassignSkills removes Skills from a User
+//
+// Like AssignSkill, a concurrent change of the skills can be lost.
+func (session *Session) UnassignSkills(user User, skillIDs ...string) error {
+	return session.UnassignSkillsContext(session.Context, user, skillIDs...)
+}
+
+// UnassignSkillsContext removes Skills from a User
+//
+// The context cancels the requests
+func (session *Session) UnassignSkillsContext(context context.Context, user User, skillIDs ...string) error {
+	return session.updateUserSkills(context, user, func(skills []SkillAssignment) []SkillAssignment {
+		removed := map[string]bool{}
+		for _, skillID := range skillIDs {
+			removed[skillID] = true
+		}
+		kept := make([]SkillAssignment, 0, len(skills))
+		for _, skill := range skills {
+			if !removed[skill.ID.ID] {
+				kept = append(kept, skill)
+			}
+		}
+		return kept
+	})
+}
+
+// GetWorkgroupActivations retrieves the Workgroup activations of a User
+func (session *Session) GetWorkgroupActivations(user User) ([]WorkgroupActivation, error) {
+	return session.GetWorkgroupActivationsContext(session.Context, user)
+}
+
+// GetWorkgroupActivationsContext retrieves the Workgroup activations of a User
+//
+// The context cancels the request
+func (session *Session) GetWorkgroupActivationsContext(context context.Context, user User) ([]WorkgroupActivation, error) {
+	if len(user.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("user")
+	}
+	results := struct {
+		Activations []WorkgroupActivation `json:"activations"`
+	}{}
+	if err := session.sendGetContext(context, "/activations/users/"+url.PathEscape(user.ID), &results); err != nil {
+		return nil, err
+	}
+	return results.Activations, nil
+}
+
+// SetWorkgroupActivation activates or deactivates a User in a Workgroup
+func (session *Session) SetWorkgroupActivation(user User, workgroupID string, activated bool) error {
+	return session.SetWorkgroupActivationContext(session.Context, user, workgroupID, activated)
+}
+
+// SetWorkgroupActivationContext activates or deactivates a User in a Workgroup
+//
+// The context cancels the request
+func (session *Session) SetWorkgroupActivationContext(context context.Context, user User, workgroupID string, activated bool) error {
+	if len(user.ID) == 0 {
+		return errors.ArgumentMissing.With("user")
+	}
+	if len(workgroupID) == 0 {
+		return errors.ArgumentMissing.With("workgroupID")
+	}
+	return session.sendPutContext(context, "/activations/users/"+url.PathEscape(user.ID), struct {
+		Activations []WorkgroupActivation `json:"activations"`
+	}{
+		Activations: []WorkgroupActivation{{Workgroup: ConfigurationID{ID: workgroupID}, Activated: activated}},
+	}, nil)
+}
+
+// updateWorkgroupMembers reads the members of a Workgroup, updates them, and sends only the members back
+func (session *Session) updateWorkgroupMembers(context context.Context, workgroupID string, update func([]ConfigurationID) []ConfigurationID) error {
+	original, err := GetConfigurationContext[WorkgroupConfiguration](context, session, workgroupID, QueryOptions{Fields: []string{"members"}})
+	if err != nil {
+		return err
+	}
+	members := update(append([]ConfigurationID{}, original.Members...))
+	if (len(members) == 0 && len(original.Members) == 0) || reflect.DeepEqual(members, original.Members) {
+		return nil
+	}
+	return session.sendConfigurationProperty(context, WorkgroupConfiguration{}.configurationPath()+"/"+url.PathEscape(workgroupID), "members", members)
+}
+
+// updateUserSkills reads the skills of a User, updates them, and sends only the skills back
+func (session *Session) updateUserSkills(context context.Context, user User, update func([]SkillAssignment) []SkillAssignment) error {
+	original, err := GetConfigurationContext[UserConfiguration](context, session, user.ID, QueryOptions{Fields: []string{"skills"}})
+	if err != nil {
+		return err
+	}
+	skills := update(append([]SkillAssignment{}, original.Skills...))
+	if (len(skills) == 0 && len(original.Skills) == 0) || reflect.DeepEqual(skills, original.Skills) {
+		return nil
+	}
+	return session.sendConfigurationProperty(context, UserConfiguration{}.configurationPath()+"/"+url.PathEscape(user.ID), "skills", skills)
+}
+
+// sendConfigurationProperty updates a single property of a configuration object
+func (session *Session) sendConfigurationProperty(context context.Context, path, name string, value interface{}) error {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return errors.JSONMarshalError.Wrap(err)
+	}
+	return session.sendPutContext(context, path, configurationPayload{map[string]json.RawMessage{name: payload}}, nil)
+}
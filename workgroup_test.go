@@ -0,0 +1,63 @@
+package icws_test
+
+import (
+	"net/http"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-icws"
+)
+
+func (suite *SessionSuite) TestCanManageWorkgroupMembers() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["GET /icws/1234/configuration/workgroups/Sales"] = `{"configurationId": {"id": "Sales"}, "members": [{"id": "agent", "displayName": "Agent"}, {"id": "other"}]}`
+	})
+
+	members, err := session.GetWorkgroupMembers("Sales")
+	suite.Require().Nil(err)
+	suite.Require().Len(members, 2)
+	suite.Assert().Equal("Agent", members[0].String())
+
+	suite.Require().Nil(session.AddWorkgroupMembers("Sales", icws.User{ID: "agent"}, icws.User{ID: "newbie"}))
+	suite.Assert().JSONEq(`{"members": [{"id": "agent", "displayName": "Agent"}, {"id": "other"}, {"id": "newbie"}]}`, server.Payload(http.MethodPut, "/icws/1234/configuration/workgroups/Sales"))
+
+	suite.Require().Nil(session.RemoveWorkgroupMembers("Sales", icws.User{ID: "other"}))
+	suite.Assert().JSONEq(`{"members": [{"id": "agent", "displayName": "Agent"}]}`, server.Payload(http.MethodPut, "/icws/1234/configuration/workgroups/Sales"))
+
+	suite.Require().Nil(session.RemoveWorkgroupMembers("Sales", icws.User{ID: "stranger"}))
+	suite.Assert().Equal(2, server.Count(http.MethodPut, "/icws/1234/configuration/workgroups/Sales"), "Nothing should be sent when nothing changes")
+}
+
+func (suite *SessionSuite) TestCanManageUserSkills() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["GET /icws/1234/configuration/users/agent"] = `{"configurationId": {"id": "agent"}, "skills": [{"id": {"id": "French"}, "proficiency": 50, "desireToUse": 50}]}`
+	})
+	agent := icws.User{ID: "agent"}
+
+	suite.Require().Nil(session.AssignSkill(agent, "French", 90, 40))
+	suite.Assert().JSONEq(`{"skills": [{"id": {"id": "French"}, "proficiency": 90, "desireToUse": 40}]}`, server.Payload(http.MethodPut, "/icws/1234/configuration/users/agent"))
+
+	suite.Require().Nil(session.AssignSkill(agent, "Spanish", 20, 10))
+	suite.Assert().JSONEq(`{"skills": [{"id": {"id": "French"}, "proficiency": 50, "desireToUse": 50}, {"id": {"id": "Spanish"}, "proficiency": 20, "desireToUse": 10}]}`, server.Payload(http.MethodPut, "/icws/1234/configuration/users/agent"))
+
+	suite.Require().Nil(session.UnassignSkills(agent, "French"))
+	suite.Assert().JSONEq(`{"skills": []}`, server.Payload(http.MethodPut, "/icws/1234/configuration/users/agent"))
+
+	err := session.AssignSkill(agent, "French", 101, 10)
+	suite.Require().NotNil(err)
+	suite.Assert().True(errors.Is(err, errors.ArgumentInvalid))
+}
+
+func (suite *SessionSuite) TestCanToggleWorkgroupActivation() {
+	session, server := suite.NewConnectedSession(func(server *FakeServer, options *icws.SessionOptions) {
+		server.Responses["GET /icws/1234/activations/users/agent"] = `{"activations": [{"workgroup": {"id": "Sales"}, "activated": true}, {"workgroup": {"id": "Support"}, "activated": false}]}`
+	})
+	agent := icws.User{ID: "agent"}
+
+	activations, err := session.GetWorkgroupActivations(agent)
+	suite.Require().Nil(err)
+	suite.Require().Len(activations, 2)
+	suite.Assert().True(activations[0].Activated)
+
+	suite.Require().Nil(session.SetWorkgroupActivation(agent, "Support", true))
+	suite.Assert().JSONEq(`{"activations": [{"workgroup": {"id": "Support"}, "activated": true}]}`, server.Payload(http.MethodPut, "/icws/1234/activations/users/agent"))
+}